@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+func agentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Add or remove agents of the current project",
+	}
+	cmd.AddCommand(agentAddCmd())
+	cmd.AddCommand(agentRemoveCmd())
+	return cmd
+}
+
+func agentAddCmd() *cobra.Command {
+	var jobs int
+	cmd := &cobra.Command{
+		Use:   "add <agent>",
+		Short: "Add an agent and install every registered skill for it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent := args[0]
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			if err := ensureSupportedAgents([]string{agent}); err != nil {
+				return err
+			}
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			projectCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(projectCfg.Agents, agent) {
+				return fmt.Errorf("agent %s is already configured", agent)
+			}
+			ownCfg, err := readProjectConfigFile(projectRoot)
+			if err != nil {
+				return err
+			}
+			ownCfg.Agents = append(ownCfg.Agents, agent)
+			if err := writeProjectConfig(projectRoot, ownCfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Added agent %s\n", agent)
+
+			// Only the new agent is installed; the others are left as they are.
+			agentCfg := projectCfg
+			agentCfg.Agents = []string{agent}
+			return syncSkills(os.Stdout, projectRoot, globalCfg.repository(), agentCfg, sortedSkillNames(projectCfg), jobs)
+		},
+	}
+	addJobsFlag(cmd, &jobs)
+	return cmd
+}
+
+func agentRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <agent>",
+		Short: "Remove an agent and delete the skills installed for it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent := args[0]
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
+			projectCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			ownCfg, err := readProjectConfigFile(projectRoot)
+			if err != nil {
+				return err
+			}
+			idx := slices.Index(ownCfg.Agents, agent)
+			if idx < 0 {
+				if slices.Contains(projectCfg.Agents, agent) {
+					return fmt.Errorf("agent %s is inherited from an enclosing %s; remove it there", agent, projectConfigName)
+				}
+				return fmt.Errorf("agent %s is not configured", agent)
+			}
+			if len(projectCfg.Agents) == 1 {
+				return fmt.Errorf("agent %s is the only agent of the project", agent)
+			}
+			ownCfg.Agents = slices.Delete(ownCfg.Agents, idx, idx+1)
+			if err := writeProjectConfig(projectRoot, ownCfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Removed agent %s\n", agent)
+			effectiveCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(effectiveCfg.Agents, agent) {
+				fmt.Fprintf(os.Stdout, "Agent %s is still inherited from an enclosing %s; its skills are kept\n", agent, projectConfigName)
+				return nil
+			}
+
+			lock, err := loadProjectLock(projectRoot)
+			if err != nil {
+				return err
+			}
+			for _, skillName := range sortedSkillNames(projectCfg) {
+				if !slices.Contains(projectCfg.skillAgents(skillName), agent) {
+					continue
+				}
+				target, err := resolveSkillTarget(projectRoot, skillName, agent, projectCfg.SkillMap[skillName].Paths)
+				if err != nil {
+					return err
+				}
+				if err := os.RemoveAll(target); err != nil {
+					return fmt.Errorf("remove skill at %s: %w", target, err)
+				}
+				fmt.Fprintf(os.Stdout, "Removed %s for %s -> %s\n", skillName, agent, target)
+				if entry, ok := lock.Skills[skillName]; ok {
+					delete(entry.Targets, agent)
+					lock.Skills[skillName] = entry
+				}
+				if len(effectiveCfg.skillAgents(skillName)) == 0 {
+					fmt.Fprintf(os.Stdout, "Skill %s is no longer installed for any agent; add one with gym add %s --agents\n", skillName, skillName)
+				}
+			}
+			return writeProjectLock(projectRoot, lock)
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAgentAddSyncsOnlyNewAgent(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex]\nskillMap:\n  alpha: {}\n  beta:\n    source: alpha\n    pi: tools/beta\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+	if _, err := runCommand(t, syncCmd()); err != nil {
+		t.Fatal(err)
+	}
+	// A local edit of the codex copy must survive adding another agent.
+	edited := filepath.Join(project, ".codex", "skills", "alpha", "SKILL.md")
+	if err := os.WriteFile(edited, []byte("local edit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, agentCmd(), "add", "pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Added agent pi\n" +
+		"Synced alpha for pi -> " + filepath.Join(project, ".pi", "skills", "alpha") + "\n" +
+		"Synced beta for pi -> " + filepath.Join(project, "tools", "beta") + "\n"
+	if output != want {
+		t.Errorf("agent add printed:\n%s\nwant:\n%s", output, want)
+	}
+	if data, _ := os.ReadFile(edited); string(data) != "local edit\n" {
+		t.Errorf("agent add synced codex: %q", data)
+	}
+	for _, rel := range []string{".pi/skills/alpha/SKILL.md", "tools/beta/SKILL.md"} {
+		if data, err := os.ReadFile(filepath.Join(project, rel)); err != nil || string(data) != "alpha\n" {
+			t.Errorf("%s = %q, %v", rel, data, err)
+		}
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Agents, []string{"codex", "pi"}) {
+		t.Errorf("agents = %v", cfg.Agents)
+	}
+	lock, err := loadProjectLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, skillName := range []string{"alpha", "beta"} {
+		if _, ok := lock.Skills[skillName].Targets["pi"]; !ok {
+			t.Errorf("lock has no pi target for %s: %+v", skillName, lock.Skills[skillName])
+		}
+	}
+
+	if _, err := runCommand(t, agentCmd(), "add", "pi"); err == nil || err.Error() != "agent pi is already configured" {
+		t.Errorf("second add = %v", err)
+	}
+	if _, err := runCommand(t, agentCmd(), "add", "emacs"); err == nil || !strings.Contains(err.Error(), `unsupported agent "emacs"`) {
+		t.Errorf("add emacs = %v", err)
+	}
+}
+
+func TestAgentRemove(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex, pi]\n" +
+		"skillMap:\n" +
+		"  alpha:\n    pi: tools/alpha\n" +
+		"  beta:\n    source: alpha\n    agents: [codex]\n" +
+		"  gamma:\n    source: alpha\n    agents: [pi]\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+	if _, err := runCommand(t, syncCmd()); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, agentCmd(), "remove", "pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Removed agent pi\n" +
+		"Removed alpha for pi -> " + filepath.Join(project, "tools", "alpha") + "\n" +
+		"Removed gamma for pi -> " + filepath.Join(project, ".pi", "skills", "gamma") + "\n" +
+		"Skill gamma is no longer installed for any agent; add one with gym add gamma --agents\n"
+	if output != want {
+		t.Errorf("agent remove printed:\n%s\nwant:\n%s", output, want)
+	}
+	for rel, exists := range map[string]bool{
+		"tools/alpha":         false,
+		".pi/skills/gamma":    false,
+		".codex/skills/alpha": true,
+		".codex/skills/beta":  true,
+		".codex/skills/gamma": false,
+		".pi/skills/beta":     false,
+		".skills.yaml":        true,
+		".skills.lock":        true,
+	} {
+		if _, err := os.Lstat(filepath.Join(project, rel)); (err == nil) != exists {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, exists)
+		}
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The override stays so adding pi again restores the layout.
+	if !slices.Equal(cfg.Agents, []string{"codex"}) || cfg.SkillMap["alpha"].Paths["pi"] != "tools/alpha" {
+		t.Errorf("config = %+v", cfg)
+	}
+	lock, err := loadProjectLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for skillName, entry := range lock.Skills {
+		if _, ok := entry.Targets["pi"]; ok {
+			t.Errorf("lock keeps the pi target of %s", skillName)
+		}
+	}
+	if _, ok := lock.Skills["alpha"].Targets["codex"]; !ok {
+		t.Errorf("lock lost the codex target of alpha: %+v", lock.Skills["alpha"])
+	}
+
+	if _, err := runCommand(t, agentCmd(), "remove", "pi"); err == nil || err.Error() != "agent pi is not configured" {
+		t.Errorf("second remove = %v", err)
+	}
+	if _, err := runCommand(t, agentCmd(), "remove", "codex"); err == nil || err.Error() != "agent codex is the only agent of the project" {
+		t.Errorf("remove last agent = %v", err)
+	}
+}
+
+func TestAgentRemoveKeepsInheritedAgent(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	root := filepath.Join(base, "mono")
+	project := filepath.Join(root, "app")
+	writeTestFiles(t, root, map[string]string{
+		projectConfigName:          "agents: [pi]\nskillMap: {}\n",
+		"app/" + projectConfigName: "agents: [codex, pi]\nskillMap:\n  alpha: {}\n",
+	})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+	if _, err := runCommand(t, syncCmd()); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, agentCmd(), "remove", "pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Removed agent pi\nAgent pi is still inherited from an enclosing .skills.yaml; its skills are kept\n"; output != want {
+		t.Errorf("agent remove printed %q, want %q", output, want)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".pi", "skills", "alpha", "SKILL.md")); err != nil {
+		t.Errorf("inherited agent lost its skill: %v", err)
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Agents, []string{"codex"}) {
+		t.Errorf("agents = %v", cfg.Agents)
+	}
+
+	_, err = runCommand(t, agentCmd(), "remove", "pi")
+	if err == nil || err.Error() != "agent pi is inherited from an enclosing .skills.yaml; remove it there" {
+		t.Errorf("remove inherited agent = %v", err)
+	}
+}
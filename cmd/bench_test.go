@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const (
+	benchSkills        = 300
+	benchFilesPerSkill = 12
+)
+
+// setupBenchProject generates a repository of benchSkills skills with
+// benchFilesPerSkill files each and a project that registers all of them
+// for two agents.
+func setupBenchProject(b *testing.B) (string, skillRepository, ProjectConfig) {
+	b.Helper()
+	base := b.TempDir()
+	b.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	repoDir := filepath.Join(base, "repo")
+	projectRoot := filepath.Join(base, "project")
+	if err := os.MkdirAll(projectRoot, 0o755); err != nil {
+		b.Fatal(err)
+	}
+	cfg := ProjectConfig{Agents: []string{"codex", "pi"}, SkillMap: map[string]SkillConfig{}}
+	for s := range benchSkills {
+		name := fmt.Sprintf("skill-%03d", s)
+		dir := filepath.Join(repoDir, name, "references")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoDir, name, "SKILL.md"), []byte("---\nname: "+name+"\n---\n"), 0o644); err != nil {
+			b.Fatal(err)
+		}
+		for f := 1; f < benchFilesPerSkill; f++ {
+			content := fmt.Sprintf("# %s reference %d\n%0512d\n", name, f, f)
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("ref-%02d.md", f)), []byte(content), 0o644); err != nil {
+				b.Fatal(err)
+			}
+		}
+		cfg.SkillMap[name] = SkillConfig{}
+	}
+	if err := writeProjectConfig(projectRoot, cfg); err != nil {
+		b.Fatal(err)
+	}
+	return projectRoot, skillRepository{Dir: repoDir, Symlinks: symlinkPreserve}, cfg
+}
+
+func removeAgentDirs(b *testing.B, projectRoot string) {
+	b.Helper()
+	for _, dir := range []string{".codex", ".pi"} {
+		if err := os.RemoveAll(filepath.Join(projectRoot, dir)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSyncSkills(b *testing.B) {
+	projectRoot, repo, cfg := setupBenchProject(b)
+	skillNames := sortedSkillNames(cfg)
+	jobs := runtime.NumCPU()
+
+	b.Run("initial", func(b *testing.B) {
+		for b.Loop() {
+			b.StopTimer()
+			removeAgentDirs(b, projectRoot)
+			b.StartTimer()
+			if err := syncSkills(io.Discard, projectRoot, repo, cfg, skillNames, jobs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unchanged", func(b *testing.B) {
+		if err := syncSkills(io.Discard, projectRoot, repo, cfg, skillNames, jobs); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if err := syncSkills(io.Discard, projectRoot, repo, cfg, skillNames, jobs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkProjectDriftSkills(b *testing.B) {
+	projectRoot, repo, cfg := setupBenchProject(b)
+	if err := syncSkills(io.Discard, projectRoot, repo, cfg, sortedSkillNames(cfg), runtime.NumCPU()); err != nil {
+		b.Fatal(err)
+	}
+	for _, jobs := range []int{1, max(2, runtime.NumCPU())} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for b.Loop() {
+				drifted, err := projectDriftSkills(projectRoot, repo, jobs)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(drifted) != 0 {
+					b.Fatalf("%d skills drift after sync", len(drifted))
+				}
+			}
+		})
+	}
+}
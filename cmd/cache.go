@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const hashCacheName = "hashes.json"
+
+// hashCacheVersion is bumped whenever the cached hash format changes so old
+// caches are discarded instead of misread.
+const hashCacheVersion = 1
+
+// hashCacheRacyWindow skips caching files modified this recently, since a
+// second write within the same mtime tick would go unnoticed.
+const hashCacheRacyWindow = 2 * time.Second
+
+// hashCacheMaxAge drops entries that were not used for this long.
+const hashCacheMaxAge = 30 * 24 * time.Hour
+
+// hashCacheTouchInterval limits how often a cache hit refreshes the entry's
+// last-used time, so repeated runs do not rewrite an unchanged cache.
+const hashCacheTouchInterval = 24 * time.Hour
+
+type hashCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Dev     uint64 `json:"dev"`
+	Inode   uint64 `json:"inode"`
+	Hash    string `json:"hash"`
+	Used    int64  `json:"used"`
+}
+
+type hashCacheFile struct {
+	Version int                       `json:"version"`
+	Entries map[string]hashCacheEntry `json:"entries"`
+}
+
+// hashCache maps file paths to content hashes. An entry is only trusted
+// while the file's size, mtime and inode are unchanged.
+type hashCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]hashCacheEntry
+	dirty   bool
+}
+
+var (
+	fileHashesOnce sync.Once
+	fileHashes     *hashCache
+)
+
+// sharedHashCache returns the process-wide hash cache, loading it on first
+// use. Without a usable cache directory the cache only lives in memory.
+func sharedHashCache() *hashCache {
+	fileHashesOnce.Do(func() {
+		fileHashes = &hashCache{entries: map[string]hashCacheEntry{}}
+		path, err := hashCachePath()
+		if err != nil {
+			return
+		}
+		fileHashes.path = path
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		var stored hashCacheFile
+		if err := json.Unmarshal(data, &stored); err != nil || stored.Version != hashCacheVersion {
+			return
+		}
+		if stored.Entries != nil {
+			fileHashes.entries = stored.Entries
+		}
+	})
+	return fileHashes
+}
+
+func hashCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, hashCacheName), nil
+}
+
+// hash returns the content hash of a regular file, reading it only when the
+// cache has no valid entry.
+func (c *hashCache) hash(path string, info fs.FileInfo) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	id, _ := fileIdentity(info)
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[absPath]
+	if ok && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() && entry.Dev == id.dev && entry.Inode == id.ino {
+		if now.Sub(time.Unix(entry.Used, 0)) > hashCacheTouchInterval {
+			entry.Used = now.Unix()
+			c.entries[absPath] = entry
+			c.dirty = true
+		}
+		c.mu.Unlock()
+		return entry.Hash, nil
+	}
+	c.mu.Unlock()
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	if now.Sub(info.ModTime()) < hashCacheRacyWindow {
+		return hash, nil
+	}
+
+	c.mu.Lock()
+	c.entries[absPath] = hashCacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Dev:     id.dev,
+		Inode:   id.ino,
+		Hash:    hash,
+		Used:    now.Unix(),
+	}
+	c.dirty = true
+	c.mu.Unlock()
+	return hash, nil
+}
+
+// save writes the cache back to disk if it changed. It first drops
+// entries that were not used for hashCacheMaxAge or whose file is gone,
+// which also bounds the cache of a long-running gym watch.
+func (c *hashCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cutoff := time.Now().Add(-hashCacheMaxAge).Unix()
+	for path, entry := range c.entries {
+		if entry.Used < cutoff {
+			delete(c.entries, path)
+			c.dirty = true
+		} else if _, err := os.Lstat(path); os.IsNotExist(err) {
+			delete(c.entries, path)
+			c.dirty = true
+		}
+	}
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(hashCacheFile{Version: hashCacheVersion, Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("marshal hash cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	if err := writeFileAtomic(c.path, data, 0o644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// saveHashCache persists the shared cache if it was used by this process.
+func saveHashCache() error {
+	if fileHashes == nil {
+		return nil
+	}
+	return fileHashes.save()
+}
+
+func cacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local file hash cache",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Delete the file hash cache",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := hashCachePath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove hash cache %s: %w", path, err)
+			}
+			fmt.Fprintf(os.Stdout, "Cleared %s\n", path)
+			return nil
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file and backdates it past the racy window.
+func writeAged(t *testing.T, path, content string, modTime time.Time) os.FileInfo {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestHashCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.md")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	cache := &hashCache{entries: map[string]hashCacheEntry{}}
+
+	hashOf := func(info os.FileInfo) string {
+		t.Helper()
+		hash, err := cache.hash(path, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	aaa := hashOf(writeAged(t, path, "aaa", old))
+
+	// Same size, mtime and inode: the cached hash is trusted without reading.
+	if got := hashOf(writeAged(t, path, "bbb", old)); got != aaa {
+		t.Error("unchanged size and mtime missed the cache")
+	}
+	if got := hashOf(writeAged(t, path, "bbbb", old)); got == aaa {
+		t.Error("size change kept the cached hash")
+	}
+	bbbb := hashOf(writeAged(t, path, "cccc", old.Add(time.Second)))
+	if bbbb == aaa || bbbb != hashOf(writeAged(t, path, "cccc", old.Add(time.Second))) {
+		t.Error("mtime change kept the cached hash")
+	}
+	if runtime.GOOS != "windows" {
+		replacement := filepath.Join(dir, "replacement.md")
+		writeAged(t, replacement, "dddd", old.Add(time.Second))
+		if err := os.Rename(replacement, path); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hashOf(info) == bbbb {
+			t.Error("inode change kept the cached hash")
+		}
+	}
+
+	fresh := filepath.Join(dir, "fresh.md")
+	if err := os.WriteFile(fresh, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.hash(fresh, info); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.entries[fresh]; ok {
+		t.Error("a file inside the racy window was cached")
+	}
+}
+
+func TestHashCacheSavePrunesEntries(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.md")
+	writeAged(t, kept, "kept", time.Now().Add(-time.Hour))
+	now := time.Now().Unix()
+	cache := &hashCache{
+		path: filepath.Join(dir, "cache", hashCacheName),
+		entries: map[string]hashCacheEntry{
+			kept:                             {Hash: "k", Used: now},
+			filepath.Join(dir, "deleted.md"): {Hash: "d", Used: now},
+			filepath.Join(dir, "unused.md"):  {Hash: "u", Used: time.Now().Add(-2 * hashCacheMaxAge).Unix()},
+		},
+	}
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored hashCacheFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Entries) != 1 || stored.Entries[kept].Hash != "k" {
+		t.Errorf("saved entries = %v, want only %s", stored.Entries, kept)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path, err := hashCachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, filepath.Dir(path), map[string]string{hashCacheName: "{}"})
+	cmd := cacheCmd()
+	cmd.SetArgs([]string{"clear"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("hash cache still exists: %v", err)
+	}
+	// Clearing a missing cache is not an error.
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
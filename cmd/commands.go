@@ -3,71 +3,191 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func initCmd() *cobra.Command {
-	return &cobra.Command{
+	var agentNames []string
+	var repo string
+	var skillNames []string
+	var yes bool
+	var force bool
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize a project for skill management",
+		Long: "Initialize a project for skill management.\n\n" +
+			"Without --agents, agents whose directories already exist in the project\n" +
+			"(.codex, .kilocode, .pi) are offered, or used directly with --yes. With\n" +
+			"--yes gym never prompts and fails instead when information is missing.",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectRoot, err := os.Getwd()
+			projectRoot, err := startDir()
 			if err != nil {
-				return fmt.Errorf("resolve project root: %w", err)
+				return err
 			}
-			globalExists, err := globalConfigExists()
-			if err != nil {
+			if err := initGlobalConfig(repo, yes); err != nil {
 				return err
 			}
-			if !globalExists {
-				repo, err := promptSkillRepository(os.Stdin, os.Stdout)
-				if err != nil {
-					return err
-				}
-				if err := writeGlobalConfig(GlobalConfig{SkillRepository: repo}); err != nil {
+			for _, skillName := range skillNames {
+				if err := validateSkillName(skillName); err != nil {
 					return err
 				}
-				globalPath, err := globalConfigPath()
-				if err != nil {
-					return err
-				}
-				fmt.Fprintf(os.Stdout, "Created %s\n", globalPath)
 			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
 			exists, err := projectConfigExists(projectRoot)
 			if err != nil {
 				return err
 			}
-			if exists {
-				return errors.New(".skills.yaml already exists")
+			if exists && !force {
+				return errors.New(".skills.yaml already exists (use --force to merge into it)")
 			}
 
-			agents, err := promptAgents(os.Stdin, os.Stdout)
-			if err != nil {
-				return err
+			var agents []string
+			if cmd.Flags().Changed("agents") {
+				agents, err = normalizeAgents(agentNames)
+			} else {
+				agents, err = chooseAgents(projectRoot, yes)
 			}
-			if err := ensureSupportedAgents(agents); err != nil {
+			if err != nil {
 				return err
 			}
 
-			cfg := ProjectConfig{
-				Agents:   agents,
-				SkillMap: map[string]map[string]string{},
+			cfg := ProjectConfig{SkillMap: map[string]SkillConfig{}}
+			if exists {
+				if cfg, err = readProjectConfigFile(projectRoot); err != nil {
+					return err
+				}
+			}
+			added := make([]string, 0, len(agents))
+			for _, agent := range agents {
+				if !slices.Contains(cfg.Agents, agent) {
+					cfg.Agents = append(cfg.Agents, agent)
+					added = append(added, agent)
+				}
 			}
 			if err := writeProjectConfig(projectRoot, cfg); err != nil {
 				return err
 			}
-			fmt.Fprintln(os.Stdout, "Created .skills.yaml")
+			if exists {
+				fmt.Fprintf(os.Stdout, "Updated .skills.yaml (agents: %s)\n", strings.Join(cfg.Agents, ", "))
+				if len(added) > 0 && len(cfg.SkillMap) > 0 {
+					fmt.Fprintln(os.Stdout, "Run gym sync to install skills for added agents")
+				}
+			} else {
+				fmt.Fprintln(os.Stdout, "Created .skills.yaml")
+			}
+			if err := registerProject(projectRoot); err != nil {
+				return err
+			}
+			for _, skillName := range skillNames {
+				if err := addSkill(projectRoot, skillName, addOptions{}); err != nil {
+					return err
+				}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().StringSliceVar(&agentNames, "agents", nil, "comma-separated agents used in the project")
+	cmd.Flags().StringVar(&repo, "repo", "", "skill repository to record when no global config exists")
+	cmd.Flags().StringSliceVar(&skillNames, "skills", nil, "comma-separated skills to add after initializing")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "never prompt; use detected agents")
+	cmd.Flags().BoolVar(&force, "force", false, "merge into an existing .skills.yaml")
+	return cmd
+}
+
+// initGlobalConfig creates the global config on the first gym init, from
+// repo or by prompting for the repository.
+func initGlobalConfig(repo string, yes bool) error {
+	globalExists, err := globalConfigExists()
+	if err != nil {
+		return err
+	}
+	if globalExists {
+		if repo == "" {
+			return nil
+		}
+		cfg, err := readGlobalConfigFile()
+		if err != nil {
+			return err
+		}
+		abs, err := validateSkillRepository(repo)
+		if err != nil {
+			return err
+		}
+		if abs != cfg.SkillRepository {
+			return fmt.Errorf("global config already uses skill repository %s; change it with gym config set skillRepository", cfg.SkillRepository)
+		}
+		return nil
+	}
+	if repo == "" && os.Getenv(envSkillRepository) != "" {
+		return nil
+	}
+	if repo != "" {
+		repo, err = validateSkillRepository(repo)
+	} else if yes {
+		err = errors.New("no global config exists; pass --repo or set " + envSkillRepository)
+	} else {
+		repo, err = promptSkillRepository(stdinReader, os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if err := createGlobalConfig(GlobalConfig{SkillRepository: repo}); err != nil {
+		return err
+	}
+	globalPath, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Created %s\n", globalPath)
+	return nil
+}
+
+// chooseAgents offers the agents detected in the project, falling back to
+// the selection prompt.
+func chooseAgents(projectRoot string, yes bool) ([]string, error) {
+	detected, err := detectAgents(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if yes {
+		if len(detected) == 0 {
+			return nil, errors.New("no agent directories detected; pass --agents")
+		}
+		fmt.Fprintf(os.Stdout, "Detected agents: %s\n", strings.Join(detected, ", "))
+		return detected, nil
+	}
+	if len(detected) > 0 {
+		ok, err := promptConfirm(stdinReader, os.Stdout, "Detected agents "+strings.Join(detected, ", ")+". Use them?")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return detected, nil
+		}
+	}
+	agents, err := promptAgents(stdinReader, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return agents, ensureSupportedAgents(agents)
 }
 
 func listCmd() *cobra.Command {
-	return &cobra.Command{
+	var unused bool
+	var dir string
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available skills in the central repository",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -75,39 +195,29 @@ func listCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			info, err := os.Stat(globalCfg.SkillRepository)
-			if err != nil {
-				return fmt.Errorf("stat skill repository %s: %w", globalCfg.SkillRepository, err)
-			}
-			if !info.IsDir() {
-				return fmt.Errorf("skill repository %s is not a directory", globalCfg.SkillRepository)
-			}
-
-			entries, err := os.ReadDir(globalCfg.SkillRepository)
+			skills, err := repositorySkills(globalCfg.SkillRepository)
 			if err != nil {
-				return fmt.Errorf("read skill repository %s: %w", globalCfg.SkillRepository, err)
+				return err
 			}
 
-			skills := make([]string, 0, len(entries))
-			for _, entry := range entries {
-				path := filepath.Join(globalCfg.SkillRepository, entry.Name())
-				info, err := os.Stat(path)
+			if unused {
+				projects, err := discoverProjects(globalCfg, dir)
 				if err != nil {
-					return fmt.Errorf("stat repository entry %s: %w", path, err)
-				}
-				if !info.IsDir() {
-					continue
+					return err
 				}
-				hasSkillFile, err := dirHasSkillFile(path)
+				used, err := usedSkills(projects)
 				if err != nil {
-					return fmt.Errorf("inspect skill directory %s: %w", path, err)
+					return err
 				}
-				if hasSkillFile {
-					skills = append(skills, entry.Name())
+				skills = slices.DeleteFunc(skills, func(skill string) bool {
+					return used[skill]
+				})
+				if len(skills) == 0 {
+					fmt.Fprintf(os.Stdout, "No unused skills found in %s\n", globalCfg.SkillRepository)
+					return nil
 				}
 			}
 
-			sort.Strings(skills)
 			if len(skills) == 0 {
 				fmt.Fprintf(os.Stdout, "No skills found in %s\n", globalCfg.SkillRepository)
 				return nil
@@ -118,60 +228,250 @@ func listCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&unused, "unused", false, "only list skills that no project installs")
+	cmd.Flags().StringVar(&dir, "dir", "", "with --unused, scan this directory tree instead of registered projects")
+	return cmd
+}
+
+// repositorySkills returns the sorted names of the directories in the
+// repository that contain a SKILL.md file.
+func repositorySkills(skillRepo string) ([]string, error) {
+	info, err := os.Stat(skillRepo)
+	if err != nil {
+		return nil, fmt.Errorf("stat skill repository %s: %w", skillRepo, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("skill repository %s is not a directory", skillRepo)
+	}
+
+	entries, err := os.ReadDir(skillRepo)
+	if err != nil {
+		return nil, fmt.Errorf("read skill repository %s: %w", skillRepo, err)
+	}
+
+	skills := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(skillRepo, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat repository entry %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			continue
+		}
+		hasSkillFile, err := dirHasSkillFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("inspect skill directory %s: %w", path, err)
+		}
+		if hasSkillFile {
+			skills = append(skills, entry.Name())
+		}
+	}
+	sort.Strings(skills)
+	return skills, nil
 }
 
 func addCmd() *cobra.Command {
-	return &cobra.Command{
+	var mode string
+	var agentNames []string
+	var as string
+	var vars map[string]string
+	cmd := &cobra.Command{
 		Use:   "add <skill-name>",
 		Short: "Add a skill from the central repository",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			skillName := args[0]
-			projectRoot, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("resolve project root: %w", err)
-			}
-			globalCfg, err := loadGlobalConfig()
-			if err != nil {
+			if err := validateSkillName(skillName); err != nil {
 				return err
 			}
-			projectCfg, err := loadProjectConfig(projectRoot)
+			projectRoot, err := findProjectRoot()
 			if err != nil {
 				return err
 			}
-			if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
 				return err
 			}
-
-			skillSrc := filepath.Join(globalCfg.SkillRepository, skillName)
-			if _, err := os.Stat(skillSrc); err != nil {
-				return fmt.Errorf("skill %q not found in repository: %w", skillName, err)
+			defer stateLock.release()
+			opts := addOptions{Mode: mode, SetMode: cmd.Flags().Changed("mode"), As: as, Vars: vars}
+			if err := validateVarNames(vars); err != nil {
+				return fmt.Errorf("--var: %w", err)
 			}
-
-			if projectCfg.SkillMap == nil {
-				projectCfg.SkillMap = map[string]map[string]string{}
-			}
-			if _, ok := projectCfg.SkillMap[skillName]; !ok {
-				projectCfg.SkillMap[skillName] = map[string]string{}
+			if as != "" {
+				if err := validateSkillName(as); err != nil {
+					return fmt.Errorf("--as: %w", err)
+				}
 			}
-
-			for _, agent := range projectCfg.Agents {
-				target, err := resolveSkillTarget(projectRoot, skillName, agent, projectCfg.SkillMap[skillName])
-				if err != nil {
+			if cmd.Flags().Changed("agents") {
+				if opts.Agents, err = normalizeAgents(agentNames); err != nil {
 					return err
 				}
-				if err := copySkillDir(skillSrc, target); err != nil {
-					return fmt.Errorf("copy skill to %s: %w", target, err)
-				}
-				fmt.Fprintf(os.Stdout, "Synced %s for %s -> %s\n", skillName, agent, target)
+				opts.SetAgents = true
 			}
+			return addSkill(projectRoot, skillName, opts)
+		},
+	}
+	cmd.Flags().StringVar(&mode, "mode", "", "install mode for this skill (copy, link, hardlink or reflink)")
+	cmd.Flags().StringSliceVar(&agentNames, "agents", nil, "comma-separated project agents to install this skill for")
+	cmd.Flags().StringVar(&as, "as", "", "install the skill under this local name")
+	cmd.Flags().StringToStringVar(&vars, "var", nil, "set a template variable for this skill (name=value, repeatable)")
+	return cmd
+}
+
+// addOptions are the per-skill settings given to gym add.
+type addOptions struct {
+	Mode      string
+	SetMode   bool
+	Agents    []string
+	SetAgents bool
+	As        string
+	Vars      map[string]string
+}
+
+// addSkill installs a repository skill for every agent of the project and
+// registers it in .skills.yaml, under opts.As when set. The caller holds
+// the project lock.
+func addSkill(projectRoot, sourceName string, opts addOptions) error {
+	skillName := sourceName
+	if opts.As != "" {
+		skillName = opts.As
+	}
+	globalCfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	projectCfg, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+		return err
+	}
 
-			if err := writeProjectConfig(projectRoot, projectCfg); err != nil {
+	skillSrc := globalCfg.repository().skill(sourceName)
+	if _, err := os.Stat(skillSrc.Dir); err != nil {
+		return fmt.Errorf("skill %q not found in repository: %w", sourceName, err)
+	}
+
+	if err := validateInstallSettings(opts.Mode, ""); err != nil {
+		return err
+	}
+	ownCfg, err := readProjectConfigFile(projectRoot)
+	if err != nil {
+		return err
+	}
+	if ownCfg.SkillMap == nil {
+		ownCfg.SkillMap = map[string]SkillConfig{}
+	}
+	skillCfg, registered := projectCfg.SkillMap[skillName]
+	if registered && projectCfg.sourceName(skillName) != sourceName {
+		return fmt.Errorf("skill %s is already installed from %s; choose another name with --as", skillName, projectCfg.sourceName(skillName))
+	}
+	if skillName != sourceName {
+		skillCfg.Source = sourceName
+	}
+	_, owned := ownCfg.SkillMap[skillName]
+	previousAgents := projectCfg.skillAgents(skillName)
+	if opts.SetMode {
+		skillCfg.Mode = opts.Mode
+	}
+	if len(opts.Vars) > 0 {
+		vars := map[string]string{}
+		for name, value := range skillCfg.Vars {
+			vars[name] = value
+		}
+		for name, value := range opts.Vars {
+			vars[name] = value
+		}
+		skillCfg.Vars = vars
+	}
+	meta, err := readSkillMetadata(skillSrc.Dir)
+	if err != nil {
+		return err
+	}
+	setAgents := opts.SetAgents
+	if opts.SetAgents {
+		for _, agent := range opts.Agents {
+			if !slices.Contains(projectCfg.Agents, agent) {
+				return fmt.Errorf("agent %s is not configured in .skills.yaml", agent)
+			}
+		}
+		if _, excluded := meta.compatibleAgents(opts.Agents); len(excluded) > 0 {
+			return fmt.Errorf("skill %s does not support %s (its SKILL.md lists %s)", skillName, strings.Join(excluded, ", "), strings.Join(meta.Agents, ", "))
+		}
+		skillCfg.Agents = opts.Agents
+	} else if len(skillCfg.Agents) == 0 {
+		// A compatibility declaration that rules out project agents is
+		// recorded, so the config shows where the skill is installed.
+		compatible, excluded := meta.compatibleAgents(projectCfg.Agents)
+		if len(excluded) > 0 {
+			skillCfg.Agents = compatible
+			setAgents = true
+			warnExcludedAgents(os.Stdout, skillName, excluded)
+		}
+	}
+	// Skills inherited from an enclosing config are only copied into
+	// this project's config when their settings change.
+	writeConfig := owned || !registered || opts.SetMode || setAgents || len(opts.Vars) > 0
+	projectCfg.SkillMap[skillName] = skillCfg
+	ownCfg.SkillMap[skillName] = skillCfg
+	skillSrc = globalCfg.repository().projectSkill(projectCfg, skillName)
+	installMode, linkStyle := projectCfg.installMode(skillName)
+	agents, excluded, err := installAgents(projectCfg, skillName, skillSrc)
+	if err != nil {
+		return err
+	}
+	if len(agents) == 0 {
+		return fmt.Errorf("skill %s supports none of the project agents (%s)", skillName, strings.Join(projectCfg.Agents, ", "))
+	}
+	if !setAgents {
+		warnExcludedAgents(os.Stdout, skillName, excluded)
+	}
+
+	lock, err := loadProjectLock(projectRoot)
+	if err != nil {
+		return err
+	}
+	if registered {
+		// Targets of agents the skill no longer applies to are removed.
+		for _, agent := range previousAgents {
+			if slices.Contains(agents, agent) {
+				continue
+			}
+			target, err := resolveSkillTarget(projectRoot, skillName, agent, skillCfg.Paths)
+			if err != nil {
 				return err
 			}
-			return nil
-		},
+			if err := os.RemoveAll(target); err != nil {
+				return fmt.Errorf("remove skill at %s: %w", target, err)
+			}
+			delete(lock.Skills[skillName].Targets, agent)
+			fmt.Fprintf(os.Stdout, "Removed %s for %s -> %s\n", skillName, agent, target)
+		}
+	}
+	targets := map[string]string{}
+	for _, agent := range agents {
+		target, err := resolveSkillTarget(projectRoot, skillName, agent, skillCfg.Paths)
+		if err != nil {
+			return err
+		}
+		if err := installSkill(skillSrc.forAgent(agent, projectCfg.Filters[agent]), target, installMode, linkStyle); err != nil {
+			return fmt.Errorf("install skill to %s: %w", target, err)
+		}
+		targets[agent] = target
+		fmt.Fprintf(os.Stdout, "Synced %s for %s -> %s\n", skillName, agent, target)
 	}
+	if err := recordInstall(&lock, skillName, skillSrc, targets); err != nil {
+		return err
+	}
+
+	if writeConfig {
+		if err := writeProjectConfig(projectRoot, ownCfg); err != nil {
+			return err
+		}
+	}
+	return writeProjectLock(projectRoot, lock)
 }
 
 func removeCmd() *cobra.Command {
@@ -181,14 +481,15 @@ func removeCmd() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			skillName := args[0]
-			projectRoot, err := os.Getwd()
+			projectRoot, err := findProjectRoot()
 			if err != nil {
-				return fmt.Errorf("resolve project root: %w", err)
+				return err
 			}
-
+			stateLock, err := lockProject(projectRoot)
 			if err != nil {
 				return err
 			}
+			defer stateLock.release()
 			projectCfg, err := loadProjectConfig(projectRoot)
 			if err != nil {
 				return err
@@ -196,13 +497,32 @@ func removeCmd() *cobra.Command {
 			if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
 				return err
 			}
-			overrides, ok := projectCfg.SkillMap[skillName]
+			skillCfg, ok := projectCfg.SkillMap[skillName]
 			if !ok {
 				return fmt.Errorf("skill %q is not registered in .skills.yaml", skillName)
 			}
+			ownCfg, err := readProjectConfigFile(projectRoot)
+			if err != nil {
+				return err
+			}
+			if _, owned := ownCfg.SkillMap[skillName]; !owned {
+				return fmt.Errorf("skill %q is inherited from an enclosing %s; remove it there", skillName, projectConfigName)
+			}
+			delete(ownCfg.SkillMap, skillName)
+			if err := writeProjectConfig(projectRoot, ownCfg); err != nil {
+				return err
+			}
+			effectiveCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			if _, inherited := effectiveCfg.SkillMap[skillName]; inherited {
+				fmt.Fprintf(os.Stdout, "Skill %s is still inherited from an enclosing %s; run gym sync to restore its settings\n", skillName, projectConfigName)
+				return nil
+			}
 
-			for _, agent := range projectCfg.Agents {
-				target, err := resolveSkillTarget(projectRoot, skillName, agent, overrides)
+			for _, agent := range projectCfg.skillAgents(skillName) {
+				target, err := resolveSkillTarget(projectRoot, skillName, agent, skillCfg.Paths)
 				if err != nil {
 					return err
 				}
@@ -212,57 +532,171 @@ func removeCmd() *cobra.Command {
 				fmt.Fprintf(os.Stdout, "Removed %s for %s -> %s\n", skillName, agent, target)
 			}
 
-			delete(projectCfg.SkillMap, skillName)
-			if err := writeProjectConfig(projectRoot, projectCfg); err != nil {
+			lock, err := loadProjectLock(projectRoot)
+			if err != nil {
 				return err
 			}
-			return nil
+			delete(lock.Skills, skillName)
+			return writeProjectLock(projectRoot, lock)
 		},
 	}
 }
 
 func syncCmd() *cobra.Command {
-	return &cobra.Command{
+	var jobs int
+	var recursive bool
+	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Synchronize all registered skills",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectRoot, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("resolve project root: %w", err)
-			}
-			globalCfg, err := loadGlobalConfig()
-			if err != nil {
+			if err := validateJobs(jobs); err != nil {
 				return err
 			}
-			projectCfg, err := loadProjectConfig(projectRoot)
+			if recursive {
+				dir, err := startDir()
+				if err != nil {
+					return err
+				}
+				globalCfg, err := loadGlobalConfig()
+				if err != nil {
+					return err
+				}
+				projects, err := discoverProjects(globalCfg, dir)
+				if err != nil {
+					return err
+				}
+				if len(projects) == 0 {
+					return fmt.Errorf("no %s found below %s", projectConfigName, dir)
+				}
+				return syncEachProject(projects, globalCfg.repository(), jobs)
+			}
+			projectRoot, err := findProjectRoot()
 			if err != nil {
 				return err
 			}
-			if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
 				return err
 			}
-			if len(projectCfg.SkillMap) == 0 {
-				fmt.Fprintln(os.Stdout, "No skills registered in .skills.yaml")
-				return nil
-			}
+			return syncProject(projectRoot, globalCfg.repository(), jobs)
+		},
+	}
+	addJobsFlag(cmd, &jobs)
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "sync every project config below the current directory")
+	return cmd
+}
 
-			for skillName, overrides := range projectCfg.SkillMap {
-				skillSrc := filepath.Join(globalCfg.SkillRepository, skillName)
-				if _, err := os.Stat(skillSrc); err != nil {
-					return fmt.Errorf("skill %q not found in repository: %w", skillName, err)
-				}
-				for _, agent := range projectCfg.Agents {
-					target, err := resolveSkillTarget(projectRoot, skillName, agent, overrides)
-					if err != nil {
-						return err
-					}
-					if err := copySkillDir(skillSrc, target); err != nil {
-						return fmt.Errorf("copy skill to %s: %w", target, err)
-					}
-					fmt.Fprintf(os.Stdout, "Synced %s for %s -> %s\n", skillName, agent, target)
-				}
+func sortedSkillNames(cfg ProjectConfig) []string {
+	names := make([]string, 0, len(cfg.SkillMap))
+	for name := range cfg.SkillMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type installTask struct {
+	skill  string
+	agent  string
+	src    skillSource
+	target string
+}
+
+// syncSkills installs the named skills for every project agent using up to
+// jobs workers, then records the installed state in the project lock.
+func syncSkills(w io.Writer, projectRoot string, repo skillRepository, projectCfg ProjectConfig, skillNames []string, jobs int) error {
+	tasks, err := planInstallTasks(w, projectRoot, repo, projectCfg, skillNames)
+	if err != nil {
+		return err
+	}
+	return runInstallTasks(w, projectRoot, projectCfg, tasks, jobs)
+}
+
+// planInstallTasks lists the targets to install. Agents a skill's SKILL.md
+// rules out are skipped with a warning written to w.
+func planInstallTasks(w io.Writer, projectRoot string, repo skillRepository, projectCfg ProjectConfig, skillNames []string) ([]installTask, error) {
+	tasks := make([]installTask, 0, len(skillNames)*len(projectCfg.Agents))
+	for _, skillName := range skillNames {
+		skillSrc := repo.projectSkill(projectCfg, skillName)
+		if _, err := os.Stat(skillSrc.Dir); err != nil {
+			return nil, fmt.Errorf("skill %q not found in repository: %w", projectCfg.sourceName(skillName), err)
+		}
+		agents, excluded, err := installAgents(projectCfg, skillName, skillSrc)
+		if err != nil {
+			return nil, err
+		}
+		warnExcludedAgents(w, skillName, excluded)
+		for _, agent := range agents {
+			target, err := resolveSkillTarget(projectRoot, skillName, agent, projectCfg.SkillMap[skillName].Paths)
+			if err != nil {
+				return nil, err
 			}
-			return nil
-		},
+			tasks = append(tasks, installTask{skill: skillName, agent: agent, src: skillSrc.forAgent(agent, projectCfg.Filters[agent]), target: target})
+		}
+	}
+	return tasks, nil
+}
+
+// runInstallTasks installs each task using up to jobs workers, then records
+// the installed state of the touched skills in the project lock.
+func runInstallTasks(w io.Writer, projectRoot string, projectCfg ProjectConfig, tasks []installTask, jobs int) error {
+	if err := runOrdered(len(tasks), jobs, func(i int) error {
+		task := tasks[i]
+		installMode, linkStyle := projectCfg.installMode(task.skill)
+		if err := installSkill(task.src, task.target, installMode, linkStyle); err != nil {
+			return fmt.Errorf("install skill to %s: %w", task.target, err)
+		}
+		return nil
+	}, func(i int, err error) error {
+		if err != nil {
+			return err
+		}
+		task := tasks[i]
+		fmt.Fprintf(w, "Synced %s for %s -> %s\n", task.skill, task.agent, task.target)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	skillNames := make([]string, 0, len(tasks))
+	skillSources := map[string]skillSource{}
+	for _, task := range tasks {
+		if _, ok := skillSources[task.skill]; !ok {
+			skillNames = append(skillNames, task.skill)
+			// The source hash covers the whole skill, not one agent's view.
+			skillSources[task.skill] = task.src.forAgent("", AgentFilter{})
+		}
+	}
+	lock, err := loadProjectLock(projectRoot)
+	if err != nil {
+		return err
+	}
+	entries := make([]SkillLock, len(skillNames))
+	for i, skillName := range skillNames {
+		entries[i] = lock.Skills[skillName]
+	}
+	if err := runOrdered(len(skillNames), jobs, func(i int) error {
+		skillName := skillNames[i]
+		targets := map[string]string{}
+		for _, task := range tasks {
+			if task.skill == skillName {
+				targets[task.agent] = task.target
+			}
+		}
+		entry, err := lockEntry(entries[i], skillSources[skillName], targets)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+		return nil
+	}, func(i int, err error) error {
+		if err != nil {
+			return err
+		}
+		lock.Skills[skillNames[i]] = entries[i]
+		return nil
+	}); err != nil {
+		return err
 	}
+	return writeProjectLock(projectRoot, lock)
 }
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setStdin feeds input to the prompts for the rest of the test.
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+	previous := stdinReader
+	stdinReader = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { stdinReader = previous })
+}
+
+func TestDetectAgents(t *testing.T) {
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{
+		".codex/config.toml":    "",
+		".kilocode/skills/x.md": "",
+		".pi":                   "not a directory",
+	})
+	agents, err := detectAgents(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"codex", "kilo-code"}; !slices.Equal(agents, want) {
+		t.Errorf("detectAgents = %v, want %v", agents, want)
+	}
+}
+
+func TestInitChoosesAgents(t *testing.T) {
+	tests := []struct {
+		name   string
+		dirs   []string
+		args   []string
+		stdin  string
+		agents []string
+		output string
+		err    string
+	}{
+		{
+			name:   "flag",
+			dirs:   []string{".codex"},
+			args:   []string{"--agents", "pi,codex,pi"},
+			agents: []string{"pi", "codex"},
+			output: "Created .skills.yaml\n",
+		},
+		{
+			name:   "yes uses detected",
+			dirs:   []string{".pi", ".codex"},
+			args:   []string{"--yes"},
+			agents: []string{"codex", "pi"},
+			output: "Detected agents: codex, pi\nCreated .skills.yaml\n",
+		},
+		{
+			name: "yes without detected agents",
+			args: []string{"--yes"},
+			err:  "no agent directories detected; pass --agents",
+		},
+		{
+			name:   "detected confirmed",
+			dirs:   []string{".kilocode"},
+			stdin:  "y\n",
+			agents: []string{"kilo-code"},
+			output: "Detected agents kilo-code. Use them? [y/N]: Created .skills.yaml\n",
+		},
+		{
+			name:   "detected declined",
+			dirs:   []string{".kilocode"},
+			stdin:  "n\n1,3\n",
+			agents: []string{"codex", "pi"},
+			output: "Detected agents kilo-code. Use them? [y/N]: Select agents used in this project:\n" +
+				"  1) codex\n  2) kilo-code\n  3) pi\nEnter comma-separated numbers: Created .skills.yaml\n",
+		},
+		{
+			name: "unknown agent",
+			args: []string{"--agents", "emacs"},
+			err:  `unsupported agent "emacs"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _ := setupTestWorkspace(t)
+			project := filepath.Join(base, "project")
+			for _, dir := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(project, dir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.MkdirAll(project, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			projectDir = project
+			t.Cleanup(func() { projectDir = "" })
+			setStdin(t, tt.stdin)
+
+			output, err := runCommand(t, initCmd(), tt.args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("init = %v, want error %q", err, tt.err)
+				}
+				if exists, _ := projectConfigExists(project); exists {
+					t.Error("failed init wrote .skills.yaml")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != tt.output {
+				t.Errorf("init printed %q, want %q", output, tt.output)
+			}
+			cfg, err := readProjectConfigFile(project)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cfg.Agents, tt.agents) {
+				t.Errorf("agents = %v, want %v", cfg.Agents, tt.agents)
+			}
+			globalCfg, err := readGlobalConfigFile()
+			if err != nil || !slices.Contains(globalCfg.Projects, project) {
+				t.Errorf("project not registered: %v, %v", globalCfg.Projects, err)
+			}
+		})
+	}
+}
+
+func TestInitForceMergesIntoExistingConfig(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	existing := "# team skills\nagents:\n  - codex\nmode: link\nskillMap:\n  alpha: {} # pinned\n"
+	writeTestFiles(t, project, map[string]string{projectConfigName: existing})
+	path := filepath.Join(project, projectConfigName)
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	if _, err := runCommand(t, initCmd(), "--agents", "pi"); err == nil || err.Error() != ".skills.yaml already exists (use --force to merge into it)" {
+		t.Fatalf("init without --force = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != existing {
+		t.Fatalf("init without --force changed the config:\n%s", data)
+	}
+
+	output, err := runCommand(t, initCmd(), "--force", "--agents", "pi,codex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Updated .skills.yaml (agents: codex, pi)\nRun gym sync to install skills for added agents\n"; output != want {
+		t.Errorf("init --force printed %q, want %q", output, want)
+	}
+	want := "# team skills\nagents:\n  - codex\n  - pi\nmode: link\nskillMap:\n  alpha: {} # pinned\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("merged config:\n%s\nwant:\n%s", data, want)
+	}
+
+	// Merging agents that are already there changes nothing.
+	output, err = runCommand(t, initCmd(), "--force", "--agents", "codex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Updated .skills.yaml (agents: codex, pi)\n" {
+		t.Errorf("second init --force printed %q", output)
+	}
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("second merge changed the config:\n%s", data)
+	}
+}
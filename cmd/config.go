@@ -3,25 +3,224 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
 
 const projectConfigName = ".skills.yaml"
-const globalConfigName = ".gym.yaml"
+
+// Environment variables that override the global config, for CI and
+// containers where no config file is set up.
+const (
+	envConfig          = "GYM_CONFIG"
+	envSkillRepository = "GYM_SKILL_REPOSITORY"
+)
 
 type GlobalConfig struct {
-	SkillRepository string `yaml:"skillRepository"`
+	Version         int      `yaml:"version,omitempty"`
+	SkillRepository string   `yaml:"skillRepository"`
+	Symlinks        string   `yaml:"symlinks,omitempty"`
+	Projects        []string `yaml:"projects,omitempty"`
 }
 
 type ProjectConfig struct {
-	Agents   []string                     `yaml:"agents"`
-	SkillMap map[string]map[string]string `yaml:"skillMap"`
+	Version   int                    `yaml:"version,omitempty"`
+	Inherit   *bool                  `yaml:"inherit,omitempty"`
+	Agents    []string               `yaml:"agents"`
+	Mode      string                 `yaml:"mode,omitempty"`
+	LinkStyle string                 `yaml:"linkStyle,omitempty"`
+	Filters   map[string]AgentFilter `yaml:"filters,omitempty"`
+	Vars      map[string]string      `yaml:"vars,omitempty"`
+	SkillMap  map[string]SkillConfig `yaml:"skillMap"`
+}
+
+// AgentFilter narrows the files of every skill installed for one agent.
+// Patterns use .gymignore syntax; with include set, only matching files
+// and the contents of matching directories are installed.
+type AgentFilter struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// SkillConfig is a skillMap entry. Per-agent target overrides are stored
+// inline next to the skill settings, keyed by agent name. Agents, when set,
+// limits the project agents the skill is installed for. Source names the
+// repository skill when it is installed under a different local name. Vars
+// override the project-wide template variables for this skill.
+type SkillConfig struct {
+	Paths     map[string]string
+	Source    string
+	Agents    []string
+	Vars      map[string]string
+	Mode      string
+	LinkStyle string
+}
+
+func (c *SkillConfig) UnmarshalYAML(node *yaml.Node) error {
+	*c = SkillConfig{}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: skill entry must be a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "agents" {
+			if err := value.Decode(&c.Agents); err != nil {
+				return fmt.Errorf("line %d: agents: %w", value.Line, err)
+			}
+			continue
+		}
+		if key.Value == "vars" {
+			if err := value.Decode(&c.Vars); err != nil {
+				return fmt.Errorf("line %d: vars: %w", value.Line, err)
+			}
+			continue
+		}
+		var text string
+		if err := value.Decode(&text); err != nil {
+			return fmt.Errorf("line %d: %s: %w", value.Line, key.Value, err)
+		}
+		switch key.Value {
+		case "source":
+			c.Source = text
+		case "mode":
+			c.Mode = text
+		case "linkStyle":
+			c.LinkStyle = text
+		default:
+			if _, ok := supportedAgents[key.Value]; !ok {
+				return fmt.Errorf("line %d: unknown key %q (not a skill setting or supported agent)", key.Line, key.Value)
+			}
+			if c.Paths == nil {
+				c.Paths = map[string]string{}
+			}
+			c.Paths[key.Value] = text
+		}
+	}
+	return nil
+}
+
+func (c SkillConfig) MarshalYAML() (interface{}, error) {
+	out := map[string]interface{}{}
+	for agent, path := range c.Paths {
+		out[agent] = path
+	}
+	if c.Source != "" {
+		out["source"] = c.Source
+	}
+	if len(c.Agents) > 0 {
+		out["agents"] = c.Agents
+	}
+	if len(c.Vars) > 0 {
+		out["vars"] = c.Vars
+	}
+	if c.Mode != "" {
+		out["mode"] = c.Mode
+	}
+	if c.LinkStyle != "" {
+		out["linkStyle"] = c.LinkStyle
+	}
+	return out, nil
+}
+
+// sourceName returns the repository skill installed under a local name.
+func (cfg ProjectConfig) sourceName(skillName string) string {
+	if source := cfg.SkillMap[skillName].Source; source != "" {
+		return source
+	}
+	return skillName
 }
 
+// skillLabel names a skill in reports, showing its source when it is
+// installed under a different name.
+func (cfg ProjectConfig) skillLabel(skillName string) string {
+	if source := cfg.sourceName(skillName); source != skillName {
+		return skillName + " (from " + source + ")"
+	}
+	return skillName
+}
+
+// skillAgents returns the project agents a skill is installed for, in
+// project order.
+func (cfg ProjectConfig) skillAgents(skillName string) []string {
+	skill := cfg.SkillMap[skillName]
+	if len(skill.Agents) == 0 {
+		return cfg.Agents
+	}
+	agents := make([]string, 0, len(skill.Agents))
+	for _, agent := range cfg.Agents {
+		if slices.Contains(skill.Agents, agent) {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// skillVars returns the template variables set for a skill in .skills.yaml:
+// the project-wide vars overridden by the skill's own.
+func (cfg ProjectConfig) skillVars(skillName string) map[string]string {
+	vars := map[string]string{}
+	for name, value := range cfg.Vars {
+		vars[name] = value
+	}
+	for name, value := range cfg.SkillMap[skillName].Vars {
+		vars[name] = value
+	}
+	return vars
+}
+
+// installMode returns the effective install mode and link style for a
+// skill, falling back to the project-wide settings.
+func (cfg ProjectConfig) installMode(skillName string) (string, string) {
+	mode, style := cfg.Mode, cfg.LinkStyle
+	if skill, ok := cfg.SkillMap[skillName]; ok {
+		if skill.Mode != "" {
+			mode = skill.Mode
+		}
+		if skill.LinkStyle != "" {
+			style = skill.LinkStyle
+		}
+	}
+	if mode == "" {
+		mode = installModeCopy
+	}
+	if style == "" {
+		style = linkStyleRelative
+	}
+	return mode, style
+}
+
+// loadGlobalConfig returns the effective global config: the config file
+// with environment overrides applied. The file may be missing when
+// GYM_SKILL_REPOSITORY provides the repository.
 func loadGlobalConfig() (GlobalConfig, error) {
+	cfg, err := readGlobalConfigFile()
+	if err != nil && !(errors.Is(err, fs.ErrNotExist) && os.Getenv(envSkillRepository) != "") {
+		return GlobalConfig{}, err
+	}
+	if repo := os.Getenv(envSkillRepository); repo != "" {
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			return GlobalConfig{}, fmt.Errorf("resolve %s: %w", envSkillRepository, err)
+		}
+		cfg.SkillRepository = abs
+	}
+	if cfg.SkillRepository == "" {
+		return GlobalConfig{}, errors.New("global config skillRepository is empty")
+	}
+	return cfg, nil
+}
+
+// readGlobalConfigFile parses the global config file as written, without
+// environment overrides. Commands that edit the file use it so overrides
+// are never saved.
+func readGlobalConfigFile() (GlobalConfig, error) {
 	path, err := globalConfigPath()
 	if err != nil {
 		return GlobalConfig{}, err
@@ -30,12 +229,18 @@ func loadGlobalConfig() (GlobalConfig, error) {
 	if err != nil {
 		return GlobalConfig{}, fmt.Errorf("read global config %s: %w", path, err)
 	}
+	return parseGlobalConfig(path, data)
+}
+
+// parseGlobalConfig decodes and validates the content of the global config
+// at path.
+func parseGlobalConfig(path string, data []byte) (GlobalConfig, error) {
 	var cfg GlobalConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := decodeConfig(data, &cfg, globalConfigVersion, globalMigrations); err != nil {
 		return GlobalConfig{}, fmt.Errorf("parse global config %s: %w", path, err)
 	}
-	if cfg.SkillRepository == "" {
-		return GlobalConfig{}, errors.New("global config skillRepository is empty")
+	if err := validateSymlinkPolicy(cfg.Symlinks); err != nil {
+		return GlobalConfig{}, fmt.Errorf("global config %s: %w", path, err)
 	}
 	return cfg, nil
 }
@@ -45,36 +250,177 @@ func writeGlobalConfig(cfg GlobalConfig) error {
 	if err != nil {
 		return err
 	}
-	data, err := yaml.Marshal(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	// Existing files keep their schema version until gym migrate upgrades
+	// them, so an unrelated change never adds a version line.
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		cfg.Version = globalConfigVersion
+	}
+	data, err := marshalPreserving(path, cfg)
 	if err != nil {
 		return fmt.Errorf("marshal global config: %w", err)
 	}
-	return os.WriteFile(path, data, 0o644)
+	return writeFileAtomic(path, data, 0o644)
 }
 
+// createGlobalConfig writes the first global config, refusing to replace
+// one that another gym process created in the meantime.
+func createGlobalConfig(cfg GlobalConfig) error {
+	configLock, err := lockGlobalConfig()
+	if err != nil {
+		return err
+	}
+	defer configLock.release()
+	exists, err := globalConfigExists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("global config was created by another gym process; run gym init again")
+	}
+	return writeGlobalConfig(cfg)
+}
+
+// loadProjectConfig returns the effective config of a project: its own
+// .skills.yaml merged over the configs of enclosing directories.
 func loadProjectConfig(projectRoot string) (ProjectConfig, error) {
+	layers, err := projectConfigChain(projectRoot)
+	if err != nil {
+		return ProjectConfig{}, err
+	}
+	cfg := mergeProjectConfigs(layers)
+	if len(cfg.Agents) == 0 {
+		return ProjectConfig{}, errors.New("project config agents list is empty")
+	}
+	if err := validateSkillTargets(cfg); err != nil {
+		return ProjectConfig{}, err
+	}
+	return cfg, nil
+}
+
+// readProjectConfigFile parses only the .skills.yaml in projectRoot,
+// without inherited settings. Commands that edit the config use it so
+// inherited entries are never written back into a nested config.
+func readProjectConfigFile(projectRoot string) (ProjectConfig, error) {
 	path := filepath.Join(projectRoot, projectConfigName)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return ProjectConfig{}, fmt.Errorf("read project config %s: %w", path, err)
 	}
+	return parseProjectConfig(path, data)
+}
+
+// parseProjectConfig decodes and validates the content of the project
+// config at path.
+func parseProjectConfig(path string, data []byte) (ProjectConfig, error) {
 	var cfg ProjectConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := decodeConfig(data, &cfg, projectConfigVersion, projectMigrations); err != nil {
 		return ProjectConfig{}, fmt.Errorf("parse project config %s: %w", path, err)
 	}
-	if len(cfg.Agents) == 0 {
-		return ProjectConfig{}, errors.New("project config agents list is empty")
+	if err := validateInstallSettings(cfg.Mode, cfg.LinkStyle); err != nil {
+		return ProjectConfig{}, fmt.Errorf("project config %s: %w", path, err)
+	}
+	for agent, filter := range cfg.Filters {
+		if err := validateAgentFilter(filter); err != nil {
+			return ProjectConfig{}, fmt.Errorf("project config %s: filters for %s: %w", path, agent, err)
+		}
+	}
+	if err := validateVarNames(cfg.Vars); err != nil {
+		return ProjectConfig{}, fmt.Errorf("project config %s: %w", path, err)
+	}
+	for skillName, skill := range cfg.SkillMap {
+		if err := validateSkillConfig(skillName, skill); err != nil {
+			return ProjectConfig{}, fmt.Errorf("project config %s: %w", path, err)
+		}
+		if err := validateInstallSettings(skill.Mode, skill.LinkStyle); err != nil {
+			return ProjectConfig{}, fmt.Errorf("project config %s: skill %q: %w", path, skillName, err)
+		}
 	}
 	return cfg, nil
 }
 
+type projectConfigLayer struct {
+	Dir    string
+	Config ProjectConfig
+}
+
+// projectConfigChain returns the configs that apply to projectRoot, from
+// the outermost enclosing directory down to projectRoot itself. Walking up
+// stops at the first config that sets inherit: false.
+func projectConfigChain(projectRoot string) ([]projectConfigLayer, error) {
+	own, err := readProjectConfigFile(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	layers := []projectConfigLayer{{Dir: projectRoot, Config: own}}
+	inherit := own.Inherit == nil || *own.Inherit
+	for dir := filepath.Dir(projectRoot); inherit && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		exists, err := projectConfigExists(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		parent, err := readProjectConfigFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		layers = append([]projectConfigLayer{{Dir: dir, Config: parent}}, layers...)
+		inherit = parent.Inherit == nil || *parent.Inherit
+	}
+	return layers, nil
+}
+
+// mergeProjectConfigs combines config layers ordered outermost first.
+// Agents accumulate, skills, agent filters and template variables defined
+// in inner layers replace inherited entries of the same name and inner
+// install settings win.
+func mergeProjectConfigs(layers []projectConfigLayer) ProjectConfig {
+	merged := ProjectConfig{SkillMap: map[string]SkillConfig{}}
+	for _, layer := range layers {
+		for _, agent := range layer.Config.Agents {
+			if !slices.Contains(merged.Agents, agent) {
+				merged.Agents = append(merged.Agents, agent)
+			}
+		}
+		if layer.Config.Mode != "" {
+			merged.Mode = layer.Config.Mode
+		}
+		if layer.Config.LinkStyle != "" {
+			merged.LinkStyle = layer.Config.LinkStyle
+		}
+		for agent, filter := range layer.Config.Filters {
+			if merged.Filters == nil {
+				merged.Filters = map[string]AgentFilter{}
+			}
+			merged.Filters[agent] = filter
+		}
+		for name, value := range layer.Config.Vars {
+			if merged.Vars == nil {
+				merged.Vars = map[string]string{}
+			}
+			merged.Vars[name] = value
+		}
+		for skillName, skill := range layer.Config.SkillMap {
+			merged.SkillMap[skillName] = skill
+		}
+	}
+	return merged
+}
+
 func writeProjectConfig(projectRoot string, cfg ProjectConfig) error {
 	path := filepath.Join(projectRoot, projectConfigName)
-	data, err := yaml.Marshal(cfg)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		cfg.Version = projectConfigVersion
+	}
+	data, err := marshalPreserving(path, cfg)
 	if err != nil {
 		return fmt.Errorf("marshal project config: %w", err)
 	}
-	return os.WriteFile(path, data, 0o644)
+	return writeFileAtomic(path, data, 0o644)
 }
 
 func projectConfigExists(projectRoot string) (bool, error) {
@@ -103,11 +449,3 @@ func globalConfigExists() (bool, error) {
 	}
 	return false, err
 }
-
-func globalConfigPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("resolve home directory: %w", err)
-	}
-	return filepath.Join(home, globalConfigName), nil
-}
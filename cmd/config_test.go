@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProjectConfigInheritance(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		project string
+		layers  []string
+		agents  []string
+		skills  map[string]SkillConfig
+		mode    string
+		filters map[string]AgentFilter
+		vars    map[string]string
+	}{
+		{
+			name: "child adds to parent",
+			files: map[string]string{
+				".skills.yaml":       "agents: [codex]\nmode: hardlink\nvars:\n  team: core\nskillMap:\n  alpha: {}\n",
+				"app/.skills.yaml":   "agents: [pi]\nskillMap:\n  beta: {}\n",
+				"other/.skills.yaml": "agents: [kilo-code]\nskillMap:\n  gamma: {}\n",
+			},
+			project: "app",
+			layers:  []string{".", "app"},
+			agents:  []string{"codex", "pi"},
+			skills:  map[string]SkillConfig{"alpha": {}, "beta": {}},
+			mode:    installModeHardlink,
+			vars:    map[string]string{"team": "core"},
+		},
+		{
+			name: "child overrides skill, filter, mode and vars",
+			files: map[string]string{
+				".skills.yaml":     "agents: [codex]\nmode: hardlink\nfilters:\n  codex:\n    exclude: [\"*.pdf\"]\nvars:\n  team: core\nskillMap:\n  alpha:\n    mode: reflink\n",
+				"app/.skills.yaml": "agents: [codex]\nmode: copy\nfilters:\n  codex:\n    include: [\"docs/\"]\nvars:\n  team: app\nskillMap:\n  alpha:\n    source: beta\n",
+			},
+			project: "app",
+			layers:  []string{".", "app"},
+			agents:  []string{"codex"},
+			skills:  map[string]SkillConfig{"alpha": {Source: "beta"}},
+			mode:    installModeCopy,
+			filters: map[string]AgentFilter{"codex": {Include: []string{"docs/"}}},
+			vars:    map[string]string{"team": "app"},
+		},
+		{
+			name: "inherit false in child",
+			files: map[string]string{
+				".skills.yaml":     "agents: [codex]\nskillMap:\n  alpha: {}\n",
+				"app/.skills.yaml": "inherit: false\nagents: [pi]\nskillMap:\n  beta: {}\n",
+			},
+			project: "app",
+			layers:  []string{"app"},
+			agents:  []string{"pi"},
+			skills:  map[string]SkillConfig{"beta": {}},
+		},
+		{
+			name: "inherit false in middle layer",
+			files: map[string]string{
+				".skills.yaml":         "agents: [codex]\nskillMap:\n  alpha: {}\n",
+				"app/.skills.yaml":     "inherit: false\nagents: [pi]\nskillMap:\n  beta: {}\n",
+				"app/web/.skills.yaml": "agents: [kilo-code]\nskillMap:\n  gamma: {}\n",
+			},
+			project: "app/web",
+			layers:  []string{"app", "app/web"},
+			agents:  []string{"pi", "kilo-code"},
+			skills:  map[string]SkillConfig{"beta": {}, "gamma": {}},
+		},
+		{
+			name: "gap between configs",
+			files: map[string]string{
+				".skills.yaml":       "agents: [codex]\nskillMap:\n  alpha: {}\n",
+				"a/b/c/.skills.yaml": "agents: [codex]\nskillMap:\n  alpha:\n    mode: link\n",
+			},
+			project: "a/b/c",
+			layers:  []string{".", "a/b/c"},
+			agents:  []string{"codex"},
+			skills:  map[string]SkillConfig{"alpha": {Mode: installModeLink}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTestFiles(t, root, tt.files)
+			layers, err := projectConfigChain(filepath.Join(root, filepath.FromSlash(tt.project)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Directories above the test root may hold configs of their own.
+			var dirs []string
+			for _, layer := range layers {
+				if rel, err := filepath.Rel(root, layer.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+					dirs = append(dirs, filepath.ToSlash(rel))
+				}
+			}
+			if !slices.Equal(dirs, tt.layers) {
+				t.Errorf("layers = %v, want %v", dirs, tt.layers)
+			}
+			merged := mergeProjectConfigs(layers)
+			if !slices.Equal(merged.Agents, tt.agents) {
+				t.Errorf("agents = %v, want %v", merged.Agents, tt.agents)
+			}
+			if len(merged.SkillMap) != len(tt.skills) {
+				t.Errorf("skills = %v, want %v", merged.SkillMap, tt.skills)
+			}
+			for name, want := range tt.skills {
+				got, ok := merged.SkillMap[name]
+				if !ok || got.Source != want.Source || got.Mode != want.Mode {
+					t.Errorf("skill %s = %+v, want %+v", name, got, want)
+				}
+			}
+			if merged.Mode != tt.mode {
+				t.Errorf("mode = %q, want %q", merged.Mode, tt.mode)
+			}
+			for agent, want := range tt.filters {
+				got := merged.Filters[agent]
+				if !slices.Equal(got.Include, want.Include) || !slices.Equal(got.Exclude, want.Exclude) {
+					t.Errorf("filter %s = %+v, want %+v", agent, got, want)
+				}
+			}
+			for name, want := range tt.vars {
+				if merged.Vars[name] != want {
+					t.Errorf("var %s = %q, want %q", name, merged.Vars[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusShowsSkillOrigins(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	root := filepath.Join(base, "mono")
+	writeTestFiles(t, root, map[string]string{
+		".skills.yaml":     "agents: [codex]\nskillMap:\n  alpha: {}\n  beta: {}\n",
+		"app/.skills.yaml": "agents: [pi]\nskillMap:\n  beta:\n    source: alpha\n    mode: link\n",
+	})
+	app := filepath.Join(root, "app")
+	projectDir = app
+	t.Cleanup(func() { projectDir = "" })
+	output, err := runCommand(t, statusCmd())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Config files:\n  " + filepath.Join(root, projectConfigName) + "\n  " + filepath.Join(app, projectConfigName) + "\n",
+		"Agents: codex, pi\n",
+		"  alpha (mode copy, from " + filepath.Join(root, projectConfigName) + ")\n",
+		"  beta (source alpha, mode link, from " + filepath.Join(app, projectConfigName) + ")\n",
+		"    codex -> " + filepath.Join(".codex", "skills", "alpha") + "\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("status output lacks %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestSyncRecursive(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	root := filepath.Join(base, "mono")
+	writeTestFiles(t, root, map[string]string{
+		".skills.yaml":                "agents: [codex]\nskillMap:\n  alpha: {}\n",
+		"app/.skills.yaml":            "agents: [pi]\nskillMap: {}\n",
+		"isolated/.skills.yaml":       "inherit: false\nagents: [kilo-code]\nskillMap: {}\n",
+		"node_modules/x/.skills.yaml": "agents: [codex]\nskillMap:\n  alpha: {}\n",
+	})
+	projectDir = root
+	t.Cleanup(func() { projectDir = "" })
+	if output, err := runCommand(t, syncCmd(), "--recursive"); err != nil {
+		t.Fatalf("sync --recursive: %v\n%s", err, output)
+	}
+	for rel, want := range map[string]bool{
+		".codex/skills/alpha/SKILL.md":                true,
+		"app/.codex/skills/alpha/SKILL.md":            true,
+		"app/.pi/skills/alpha/SKILL.md":               true,
+		"isolated/.codex/skills/alpha/SKILL.md":       false,
+		"isolated/.kilocode/skills/alpha/SKILL.md":    false,
+		"node_modules/x/.codex/skills/alpha/SKILL.md": false,
+	} {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel)))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", rel, exists, want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const diffContextLines = 3
+
+// maxDiffCells bounds the size of the line comparison table so that huge
+// files fall back to a short "files differ" note.
+const maxDiffCells = 4_000_000
+
+type diffNode struct {
+	mode    fs.FileMode
+	path    string
+	content []byte
+}
+
+// writeDirDiff prints a unified diff turning oldSrc into newSrc and reports
+// whether any difference was found.
+func writeDirDiff(w io.Writer, oldSrc, newSrc skillSource) (bool, error) {
+	oldNodes, err := collectDiffNodes(oldSrc)
+	if err != nil {
+		return false, err
+	}
+	newNodes, err := collectDiffNodes(newSrc)
+	if err != nil {
+		return false, err
+	}
+
+	paths := make([]string, 0, len(oldNodes)+len(newNodes))
+	for rel := range oldNodes {
+		paths = append(paths, rel)
+	}
+	for rel := range newNodes {
+		if _, ok := oldNodes[rel]; !ok {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+
+	changed := false
+	for _, rel := range paths {
+		oldNode, inOld := oldNodes[rel]
+		newNode, inNew := newNodes[rel]
+		fileChanged, err := writeNodeDiff(w, rel, oldNode, inOld, newNode, inNew)
+		if err != nil {
+			return false, err
+		}
+		if fileChanged {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func collectDiffNodes(src skillSource) (map[string]diffNode, error) {
+	nodes := map[string]diffNode{}
+	if _, err := os.Stat(src.Dir); err != nil {
+		if os.IsNotExist(err) {
+			return nodes, nil
+		}
+		return nil, err
+	}
+	err := src.walk(func(entry skillEntry) error {
+		if entry.Info.IsDir() {
+			return nil
+		}
+		nodes[filepath.ToSlash(entry.Rel)] = diffNode{mode: entry.Info.Mode(), path: entry.Path, content: entry.Content}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func writeNodeDiff(w io.Writer, rel string, oldNode diffNode, inOld bool, newNode diffNode, inNew bool) (bool, error) {
+	var oldData, newData []byte
+	var err error
+	if inOld {
+		oldData, err = readDiffContent(oldNode)
+		if err != nil {
+			return false, err
+		}
+	}
+	if inNew {
+		newData, err = readDiffContent(newNode)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	modeChanged := inOld && inNew && oldNode.mode != newNode.mode
+	if inOld && inNew && !modeChanged && bytes.Equal(oldData, newData) {
+		return false, nil
+	}
+
+	oldLabel, newLabel := "a/"+rel, "b/"+rel
+	if !inOld {
+		oldLabel = "/dev/null"
+	}
+	if !inNew {
+		newLabel = "/dev/null"
+	}
+	fmt.Fprintf(w, "diff a/%s b/%s\n", rel, rel)
+	if modeChanged {
+		fmt.Fprintf(w, "mode %s -> %s\n", oldNode.mode, newNode.mode)
+	}
+	if inOld && inNew && bytes.Equal(oldData, newData) {
+		return true, nil
+	}
+	if isBinary(oldData) || isBinary(newData) || isSymlinkNode(oldNode, inOld) || isSymlinkNode(newNode, inNew) {
+		fmt.Fprintf(w, "Binary or symlink %s and %s differ\n", oldLabel, newLabel)
+		return true, nil
+	}
+
+	oldLines := splitLines(oldData)
+	newLines := splitLines(newData)
+	if len(oldLines)*len(newLines) > maxDiffCells {
+		fmt.Fprintf(w, "Files %s and %s differ\n", oldLabel, newLabel)
+		return true, nil
+	}
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldLabel, newLabel)
+	writeHunks(w, oldLines, newLines)
+	return true, nil
+}
+
+func readDiffContent(node diffNode) ([]byte, error) {
+	if node.mode&os.ModeSymlink != 0 {
+		link, err := os.Readlink(node.path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(link), nil
+	}
+	if node.content != nil {
+		return node.content, nil
+	}
+	return os.ReadFile(node.path)
+}
+
+func isSymlinkNode(node diffNode, present bool) bool {
+	return present && node.mode&os.ModeSymlink != 0
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes a line-level edit script using a longest common
+// subsequence table.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', line: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: '-', line: a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: '+', line: b[j]})
+	}
+	return ops
+}
+
+func writeHunks(w io.Writer, a, b []string) {
+	ops := diffLines(a, b)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		// Extend the hunk while changes are separated by at most twice the
+		// context, then pad it with context on both sides.
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k + 1
+				continue
+			}
+			if k-end >= 2*diffContextLines {
+				break
+			}
+		}
+		from := max(start-diffContextLines, 0)
+		to := min(end+diffContextLines, len(ops))
+
+		oldStart, newStart := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[from:to] {
+			line := op.line
+			fmt.Fprintf(w, "%c%s", op.kind, line)
+			if !strings.HasSuffix(line, "\n") {
+				fmt.Fprint(w, "\n\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+}
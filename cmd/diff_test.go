@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func numberedLines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line + "\n")
+		} else {
+			fmt.Fprintf(&b, "l%d\n", i)
+		}
+	}
+	return b.String()
+}
+
+const dirDiffGolden = `diff a/doc.md b/doc.md
+--- a/doc.md
++++ b/doc.md
+@@ -2,13 +2,13 @@
+ l2
+ l3
+ l4
+-l5
++L5
+ l6
+ l7
+ l8
+ l9
+ l10
+-l11
++L11
+ l12
+ l13
+ l14
+@@ -16,5 +16,5 @@
+ l16
+ l17
+ l18
+-l19
++L19
+ l20
+diff a/new.md b/new.md
+--- /dev/null
++++ b/new.md
+@@ -0,0 +1,2 @@
++x
++y
+diff a/noeol.md b/noeol.md
+--- a/noeol.md
++++ b/noeol.md
+@@ -1,2 +1,2 @@
+ a
+-b
++b
+\ No newline at end of file
+diff a/old.md b/old.md
+--- a/old.md
++++ /dev/null
+@@ -1,1 +0,0 @@
+-gone
+diff a/run.sh b/run.sh
+mode -rw-r--r-- -> -rwxr-xr-x
+`
+
+func TestWriteDirDiff(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeTestFiles(t, oldDir, map[string]string{
+		"doc.md":   numberedLines(20, nil),
+		"noeol.md": "a\nb\n",
+		"old.md":   "gone\n",
+		"run.sh":   "echo\n",
+		"same.md":  "same\n",
+	})
+	writeTestFiles(t, newDir, map[string]string{
+		"doc.md":   numberedLines(20, map[int]string{5: "L5", 11: "L11", 19: "L19"}),
+		"new.md":   "x\ny\n",
+		"noeol.md": "a\nb",
+		"run.sh":   "echo\n",
+		"same.md":  "same\n",
+	})
+	if err := os.Chmod(filepath.Join(oldDir, "run.sh"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(newDir, "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	changed, err := writeDirDiff(&out, plainSource(oldDir), plainSource(newDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("writeDirDiff reported no changes")
+	}
+	if out.String() != dirDiffGolden {
+		t.Errorf("diff mismatch\n got:\n%s\nwant:\n%s", out.String(), dirDiffGolden)
+	}
+
+	out.Reset()
+	changed, err = writeDirDiff(&out, plainSource(oldDir), plainSource(oldDir))
+	if err != nil || changed || out.Len() != 0 {
+		t.Errorf("identical dirs: changed %v, err %v, output %q", changed, err, out.String())
+	}
+}
+
+func TestWriteHunksMergesNearbyChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		second  int
+		headers []string
+	}{
+		// Six unchanged lines between two changes fit in the context of both.
+		{"gap of 2*context", 8, []string{"@@ -1,11 +1,11 @@"}},
+		{"gap beyond 2*context", 9, []string{"@@ -1,4 +1,4 @@", "@@ -6,7 +6,7 @@"}},
+	}
+	for _, tt := range tests {
+		a := splitLines([]byte(numberedLines(15, nil)))
+		b := splitLines([]byte(numberedLines(15, map[int]string{1: "L1", tt.second: "changed"})))
+		var out strings.Builder
+		writeHunks(&out, a, b)
+		var headers []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(line, "@@") {
+				headers = append(headers, line)
+			}
+		}
+		if strings.Join(headers, "|") != strings.Join(tt.headers, "|") {
+			t.Errorf("%s: hunks %q, want %q\n%s", tt.name, headers, tt.headers, out.String())
+		}
+	}
+}
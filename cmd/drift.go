@@ -1,13 +1,12 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,19 +15,23 @@ import (
 var errDirMismatch = errors.New("directory mismatch")
 
 func driftCmd() *cobra.Command {
-	return &cobra.Command{
+	var jobs int
+	cmd := &cobra.Command{
 		Use:   "drift",
 		Short: "List drifting skills for the current project",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectRoot, err := os.Getwd()
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			projectRoot, err := findProjectRoot()
 			if err != nil {
-				return fmt.Errorf("resolve project root: %w", err)
+				return err
 			}
 			globalCfg, err := loadGlobalConfig()
 			if err != nil {
 				return err
 			}
-			drifted, err := projectDriftSkills(projectRoot, globalCfg.SkillRepository)
+			drifted, err := projectDriftSkills(projectRoot, globalCfg.repository(), jobs)
 			if err != nil {
 				return fmt.Errorf("check drift for %s: %w", projectRoot, err)
 			}
@@ -36,14 +39,11 @@ func driftCmd() *cobra.Command {
 				fmt.Fprintln(os.Stdout, "No drifting skills found")
 				return nil
 			}
-			sort.Slice(drifted, func(i, j int) bool {
-				return drifted[i].Skill < drifted[j].Skill
-			})
 			for _, item := range drifted {
 				fmt.Fprintf(
 					os.Stdout,
 					"%s: repo=%s project=%s status=%s\n",
-					item.Skill,
+					item.Label,
 					formatModTime(item.RepoTime),
 					formatModTime(item.ProjectTime),
 					item.Status,
@@ -52,16 +52,63 @@ func driftCmd() *cobra.Command {
 			return nil
 		},
 	}
+	addJobsFlag(cmd, &jobs)
+	return cmd
 }
 
 type driftInfo struct {
 	Skill       string
+	Label       string
 	RepoTime    time.Time
 	ProjectTime time.Time
 	Status      string
 }
 
-func projectDriftSkills(projectRoot, skillRepo string) ([]driftInfo, error) {
+// targetCheck compares one installed agent target with its repository
+// skill. Checks are independent so they can run in parallel.
+type targetCheck struct {
+	skill  string
+	agent  string
+	src    skillSource
+	target string
+	mode   string
+
+	time    time.Time
+	drift   bool
+	problem string
+}
+
+func (c *targetCheck) run() error {
+	if c.mode == installModeLink {
+		status, err := linkStatus(c.src.Dir, c.target)
+		if err != nil {
+			return fmt.Errorf("inspect link %s: %w", c.target, err)
+		}
+		c.drift = status != ""
+		c.problem = status
+		return nil
+	}
+	if info, err := os.Lstat(c.target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		c.drift = true
+		c.problem = "unexpected link"
+		return nil
+	}
+	targetTime, err := latestModTime(c.target)
+	if err != nil {
+		return fmt.Errorf("read project mtime for %s: %w", c.target, err)
+	}
+	c.time = targetTime
+	match, err := dirsEqual(c.src, c.target)
+	if err != nil {
+		return err
+	}
+	c.drift = !match
+	return nil
+}
+
+// projectDriftSkills returns the drifting skills of a project sorted by
+// name, comparing up to jobs targets in parallel.
+func projectDriftSkills(projectRoot string, repo skillRepository, jobs int) ([]driftInfo, error) {
 	projectCfg, err := loadProjectConfig(projectRoot)
 	if err != nil {
 		return nil, err
@@ -72,43 +119,110 @@ func projectDriftSkills(projectRoot, skillRepo string) ([]driftInfo, error) {
 	if len(projectCfg.SkillMap) == 0 {
 		return nil, nil
 	}
-	drifted := make([]driftInfo, 0)
-	for skillName, overrides := range projectCfg.SkillMap {
-		skillSrc := filepath.Join(skillRepo, skillName)
-		if _, err := os.Stat(skillSrc); err != nil {
-			return nil, fmt.Errorf("skill %q not found in repository: %w", skillName, err)
+
+	varsTime, err := configModTime(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := loadProjectLock(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	skillNames := sortedSkillNames(projectCfg)
+	repoTimes := make([]time.Time, len(skillNames))
+	repoChanged := make([]bool, len(skillNames))
+	if err := runOrdered(len(skillNames), jobs, func(i int) error {
+		skillSrc := repo.projectSkill(projectCfg, skillNames[i])
+		if _, err := os.Stat(skillSrc.Dir); err != nil {
+			return fmt.Errorf("skill %q not found in repository: %w", projectCfg.sourceName(skillNames[i]), err)
 		}
-		repoTime, err := latestModTime(skillSrc)
+		repoTime, err := latestSourceModTime(skillSrc, varsTime)
 		if err != nil {
-			return nil, fmt.Errorf("read repository mtime for %s: %w", skillSrc, err)
+			return fmt.Errorf("read repository mtime for %s: %w", skillSrc.Dir, err)
 		}
-		projectTime := time.Time{}
-		skillHasDrift := false
-		for _, agent := range projectCfg.Agents {
-			target, err := resolveSkillTarget(projectRoot, skillName, agent, overrides)
+		repoTimes[i] = repoTime
+		// Hard-linked targets share their inodes with the repository, so an
+		// edit in the project changes both sides and the targets still
+		// match. Only the hash recorded at install time reveals it. Reflinks
+		// are copy-on-write, so their edits show up as plain drift.
+		installMode, _ := projectCfg.installMode(skillNames[i])
+		entry, ok := lock.Skills[skillNames[i]]
+		if ok && entry.Source != "" && installMode == installModeHardlink {
+			current, err := newDirHasher().hashSource(skillSrc)
 			if err != nil {
-				return nil, err
+				return fmt.Errorf("hash skill %s: %w", skillSrc.Dir, err)
 			}
-			targetTime, err := latestModTime(target)
+			repoChanged[i] = current != entry.Source
+		}
+		return nil
+	}, func(i int, err error) error {
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	checks := make([]*targetCheck, 0, len(skillNames)*len(projectCfg.Agents))
+	for _, skillName := range skillNames {
+		installMode, _ := projectCfg.installMode(skillName)
+		skillSrc := repo.projectSkill(projectCfg, skillName)
+		agents, _, err := installAgents(projectCfg, skillName, skillSrc)
+		if err != nil {
+			return nil, err
+		}
+		for _, agent := range agents {
+			target, err := resolveSkillTarget(projectRoot, skillName, agent, projectCfg.SkillMap[skillName].Paths)
 			if err != nil {
-				return nil, fmt.Errorf("read project mtime for %s: %w", target, err)
+				return nil, err
 			}
-			if targetTime.After(projectTime) {
-				projectTime = targetTime
+			checks = append(checks, &targetCheck{
+				skill:  skillName,
+				agent:  agent,
+				src:    skillSrc.forAgent(agent, projectCfg.Filters[agent]),
+				target: target,
+				mode:   installMode,
+			})
+		}
+	}
+	if err := runOrdered(len(checks), jobs, func(i int) error {
+		return checks[i].run()
+	}, func(i int, err error) error {
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	drifted := make([]driftInfo, 0)
+	for i, skillName := range skillNames {
+		projectTime := time.Time{}
+		skillHasDrift := false
+		targetProblem := ""
+		for _, check := range checks {
+			if check.skill != skillName {
+				continue
 			}
-			match, err := dirsEqual(skillSrc, target)
-			if err != nil {
-				return nil, err
+			if check.time.After(projectTime) {
+				projectTime = check.time
 			}
-			if !match {
+			if check.drift {
 				skillHasDrift = true
 			}
+			if check.problem != "" {
+				targetProblem = check.problem
+			}
 		}
-		if skillHasDrift {
-			status := driftStatus(repoTime, projectTime)
+		if skillHasDrift || repoChanged[i] {
+			status := driftStatus(repoTimes[i], projectTime)
+			if repoChanged[i] {
+				status = "repo changed since install"
+			}
+			if targetProblem != "" {
+				status = targetProblem
+				projectTime = time.Time{}
+			}
 			drifted = append(drifted, driftInfo{
 				Skill:       skillName,
-				RepoTime:    repoTime,
+				Label:       projectCfg.skillLabel(skillName),
+				RepoTime:    repoTimes[i],
 				ProjectTime: projectTime,
 				Status:      status,
 			})
@@ -117,13 +231,15 @@ func projectDriftSkills(projectRoot, skillRepo string) ([]driftInfo, error) {
 	return drifted, nil
 }
 
-func dirsEqual(src, dst string) (bool, error) {
-	info, err := os.Stat(src)
+// dirsEqual reports whether dst holds exactly the entries of src, read
+// according to its symlink policy.
+func dirsEqual(src skillSource, dst string) (bool, error) {
+	info, err := os.Stat(src.Dir)
 	if err != nil {
 		return false, err
 	}
 	if !info.IsDir() {
-		return false, fmt.Errorf("source %s is not a directory", src)
+		return false, fmt.Errorf("source %s is not a directory", src.Dir)
 	}
 	dstInfo, err := os.Stat(dst)
 	if err != nil {
@@ -132,22 +248,14 @@ func dirsEqual(src, dst string) (bool, error) {
 		}
 		return false, err
 	}
-	if !dstInfo.IsDir() {
+	if !dstInfo.IsDir() || dstInfo.Mode().Perm() != info.Mode().Perm() {
 		return false, nil
 	}
 
-	if err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == src {
-			return nil
-		}
-		rel, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		target := filepath.Join(dst, rel)
+	names := map[string]bool{}
+	if err := src.walk(func(entry skillEntry) error {
+		names[entry.Rel] = true
+		target := filepath.Join(dst, entry.Rel)
 		targetInfo, err := os.Lstat(target)
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -155,16 +263,12 @@ func dirsEqual(src, dst string) (bool, error) {
 			}
 			return err
 		}
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-		mode := info.Mode()
+		mode := entry.Info.Mode()
 		if mode&os.ModeSymlink != 0 {
 			if targetInfo.Mode()&os.ModeSymlink == 0 {
 				return errDirMismatch
 			}
-			srcLink, err := os.Readlink(path)
+			srcLink, err := os.Readlink(entry.Path)
 			if err != nil {
 				return err
 			}
@@ -177,8 +281,8 @@ func dirsEqual(src, dst string) (bool, error) {
 			}
 			return nil
 		}
-		if d.IsDir() {
-			if !targetInfo.IsDir() {
+		if mode.IsDir() {
+			if !targetInfo.IsDir() || mode.Perm() != targetInfo.Mode().Perm() {
 				return errDirMismatch
 			}
 			return nil
@@ -189,7 +293,7 @@ func dirsEqual(src, dst string) (bool, error) {
 		if mode.Perm() != targetInfo.Mode().Perm() {
 			return errDirMismatch
 		}
-		equal, err := filesEqual(path, target)
+		equal, err := entryContentEqual(entry, target)
 		if err != nil {
 			return err
 		}
@@ -215,12 +319,8 @@ func dirsEqual(src, dst string) (bool, error) {
 		if err != nil {
 			return err
 		}
-		source := filepath.Join(src, rel)
-		if _, err := os.Lstat(source); err != nil {
-			if os.IsNotExist(err) {
-				return errDirMismatch
-			}
-			return err
+		if !names[rel] {
+			return errDirMismatch
 		}
 		return nil
 	}); err != nil {
@@ -233,6 +333,22 @@ func dirsEqual(src, dst string) (bool, error) {
 	return true, nil
 }
 
+// entryContentEqual compares the content of a source entry with the regular
+// file at target. Rendered templates are compared byte for byte.
+func entryContentEqual(entry skillEntry, target string) (bool, error) {
+	if entry.Content == nil {
+		return filesEqual(entry.Path, target)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(data, entry.Content), nil
+}
+
+// filesEqual compares two regular files. Hard links to the same inode are
+// equal without reading them; otherwise content hashes come from the
+// persistent hash cache so unchanged files are not reread.
 func filesEqual(src, dst string) (bool, error) {
 	srcInfo, err := os.Stat(src)
 	if err != nil {
@@ -242,58 +358,23 @@ func filesEqual(src, dst string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if os.SameFile(srcInfo, dstInfo) {
+		return true, nil
+	}
 	if srcInfo.Size() != dstInfo.Size() {
 		return false, nil
 	}
 
-	srcFile, err := os.Open(src)
+	cache := sharedHashCache()
+	srcHash, err := cache.hash(src, srcInfo)
 	if err != nil {
 		return false, err
 	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Open(dst)
+	dstHash, err := cache.hash(dst, dstInfo)
 	if err != nil {
 		return false, err
 	}
-	defer dstFile.Close()
-
-	bufA := make([]byte, 32*1024)
-	bufB := make([]byte, 32*1024)
-	for {
-		readA, errA := srcFile.Read(bufA)
-		readB, errB := dstFile.Read(bufB)
-		if readA != readB {
-			return false, nil
-		}
-		if readA > 0 && !equalBytes(bufA[:readA], bufB[:readB]) {
-			return false, nil
-		}
-		if errA != nil || errB != nil {
-			if errA == io.EOF && errB == io.EOF {
-				return true, nil
-			}
-			if errA == io.EOF || errB == io.EOF {
-				return false, nil
-			}
-			if errA != nil {
-				return false, errA
-			}
-			return false, errB
-		}
-	}
-}
-
-func equalBytes(a, b []byte) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
-	}
-	return true
+	return srcHash == dstHash, nil
 }
 
 func latestModTime(path string) (time.Time, error) {
@@ -326,6 +407,49 @@ func latestModTime(path string) (time.Time, error) {
 	return latest, nil
 }
 
+// latestSourceModTime is latestModTime for a skill source, including the
+// targets of links its symlink policy dereferences. Rendered templates are
+// as new as the configs their variables come from, given as varsTime.
+func latestSourceModTime(src skillSource, varsTime time.Time) (time.Time, error) {
+	info, err := os.Stat(src.Dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	latest := info.ModTime()
+	if err := src.walk(func(entry skillEntry) error {
+		if entry.Info.ModTime().After(latest) {
+			latest = entry.Info.ModTime()
+		}
+		if entry.Content != nil && varsTime.After(latest) {
+			latest = varsTime
+		}
+		return nil
+	}); err != nil {
+		return time.Time{}, err
+	}
+	return latest, nil
+}
+
+// configModTime returns the latest modification time of the project
+// configs that apply to projectRoot.
+func configModTime(projectRoot string) (time.Time, error) {
+	layers, err := projectConfigChain(projectRoot)
+	if err != nil {
+		return time.Time{}, err
+	}
+	latest := time.Time{}
+	for _, layer := range layers {
+		info, err := os.Stat(filepath.Join(layer.Dir, projectConfigName))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
 func formatModTime(value time.Time) string {
 	if value.IsZero() {
 		return "missing"
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package cmd
+
+import "io/fs"
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func fileIdentity(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package cmd
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// fileIdentity returns the device and inode of a file so hard links to
+// the same content can be recognized.
+func fileIdentity(info fs.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
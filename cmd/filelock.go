@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const lockSuffix = ".lock"
+
+// lockProject takes the lock serializing changes to the config, install
+// record and installed skills of one project. It fails right away when
+// another gym process holds the lock instead of waiting.
+func lockProject(projectRoot string) (*fileLock, error) {
+	return acquireFileLock(filepath.Join(projectRoot, projectConfigName+lockSuffix))
+}
+
+// lockGlobalConfig takes the lock serializing read-modify-write cycles of
+// the global config.
+func lockGlobalConfig() (*fileLock, error) {
+	path, err := globalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	return acquireFileLock(path + lockSuffix)
+}
+
+// writeLockOwner records the current process in a lock file so a
+// competing process can name it in its error.
+func writeLockOwner(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+func lockHeldError(path string) error {
+	holder := "another gym process"
+	if data, err := os.ReadFile(path); err == nil {
+		if pid := strings.TrimSpace(string(data)); pid != "" {
+			holder = fmt.Sprintf("another gym process (pid %s)", pid)
+		}
+	}
+	return fmt.Errorf("%s holds %s; try again once it finishes", holder, path)
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+// acquireFileLock creates path exclusively. Unlike flock, the file
+// outlives a crashed process and has to be deleted by hand.
+func acquireFileLock(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("%w (delete it if no gym process is running)", lockHeldError(path))
+		}
+		return nil, fmt.Errorf("create lock file %s: %w", path, err)
+	}
+	if err := writeLockOwner(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("write lock file %s: %w", path, err)
+	}
+	return &fileLock{path: path, file: file}, nil
+}
+
+func (l *fileLock) release() error {
+	closeErr := l.file.Close()
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove lock file %s: %w", l.path, err)
+	}
+	return closeErr
+}
@@ -0,0 +1,55 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+// acquireFileLock takes an exclusive flock on path, creating the file.
+// The kernel drops the lock if the process dies, so a crash never leaves
+// a project locked.
+func acquireFileLock(path string) (*fileLock, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open lock file %s: %w", path, err)
+		}
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			file.Close()
+			if errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, lockHeldError(path)
+			}
+			return nil, fmt.Errorf("lock %s: %w", path, err)
+		}
+		// The previous holder removes the file on release; if that happened
+		// between our open and flock, we locked a stale inode and retry.
+		locked, statErr := file.Stat()
+		current, err := os.Stat(path)
+		if statErr == nil && err == nil && os.SameFile(locked, current) {
+			if err := writeLockOwner(file); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("write lock file %s: %w", path, err)
+			}
+			return &fileLock{path: path, file: file}, nil
+		}
+		file.Close()
+	}
+}
+
+func (l *fileLock) release() error {
+	removeErr := os.Remove(l.path)
+	closeErr := l.file.Close()
+	if removeErr != nil && !os.IsNotExist(removeErr) {
+		return fmt.Errorf("remove lock file %s: %w", l.path, removeErr)
+	}
+	return closeErr
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// skillMetadata holds the SKILL.md frontmatter keys gym understands. Other
+// keys, such as name and description, are meant for the agents. Templates
+// selects the files rendered with text/template, and Vars declares their
+// variables with default values; a variable without a value must be set in
+// .skills.yaml.
+type skillMetadata struct {
+	Agents    []string           `yaml:"agents"`
+	Templates []string           `yaml:"templates"`
+	Vars      map[string]*string `yaml:"vars"`
+}
+
+// findSkillFile returns the path of the SKILL.md file of a skill, matched
+// case-insensitively, or an empty string if there is none.
+func findSkillFile(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.EqualFold(entry.Name(), "skill.md") {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+	return "", nil
+}
+
+// readSkillMetadata parses the frontmatter of the SKILL.md in dir. Skills
+// without a SKILL.md or without frontmatter have empty metadata.
+func readSkillMetadata(dir string) (skillMetadata, error) {
+	var meta skillMetadata
+	path, err := findSkillFile(dir)
+	if err != nil || path == "" {
+		return meta, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return meta, err
+	}
+	frontmatter, ok := splitFrontmatter(data)
+	if !ok {
+		return meta, nil
+	}
+	if err := yaml.Unmarshal(frontmatter, &meta); err != nil {
+		return meta, fmt.Errorf("parse frontmatter of %s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// splitFrontmatter returns the YAML between a leading "---" line and the
+// next "---" or "..." line.
+func splitFrontmatter(data []byte) ([]byte, bool) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines) == 0 || string(bytes.TrimRight(lines[0], "\r\n")) != "---" {
+		return nil, false
+	}
+	var frontmatter []byte
+	for _, line := range lines[1:] {
+		switch string(bytes.TrimRight(line, " \r\n")) {
+		case "---", "...":
+			return frontmatter, true
+		}
+		frontmatter = append(frontmatter, line...)
+	}
+	return nil, false
+}
+
+// compatibleAgents narrows agents to those a skill declares support for.
+// excluded lists the agents the declaration rules out.
+func (m skillMetadata) compatibleAgents(agents []string) (compatible, excluded []string) {
+	if len(m.Agents) == 0 {
+		return agents, nil
+	}
+	for _, agent := range agents {
+		if slices.Contains(m.Agents, agent) {
+			compatible = append(compatible, agent)
+		} else {
+			excluded = append(excluded, agent)
+		}
+	}
+	return compatible, excluded
+}
+
+// installAgents returns the agents a skill is installed for: those of its
+// skillMap entry that its SKILL.md declares support for. excluded lists the
+// agents ruled out by the declaration.
+func installAgents(projectCfg ProjectConfig, skillName string, src skillSource) (agents, excluded []string, err error) {
+	meta, err := readSkillMetadata(src.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	agents, excluded = meta.compatibleAgents(projectCfg.skillAgents(skillName))
+	return agents, excluded, nil
+}
+
+func warnExcludedAgents(w io.Writer, skillName string, excluded []string) {
+	if len(excluded) > 0 {
+		fmt.Fprintf(w, "warning: skill %s does not support %s; skipping\n", skillName, strings.Join(excluded, ", "))
+	}
+}
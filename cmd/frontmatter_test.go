@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInstallAgents(t *testing.T) {
+	tests := []struct {
+		name     string
+		selected []string
+		declared string
+		agents   []string
+		excluded []string
+	}{
+		{name: "no selection or declaration", agents: []string{"codex", "kilo-code", "pi"}},
+		{name: "selection", selected: []string{"pi", "codex"}, agents: []string{"codex", "pi"}},
+		{name: "declaration", declared: "[pi, codex]", agents: []string{"codex", "pi"}, excluded: []string{"kilo-code"}},
+		{name: "intersection", selected: []string{"codex", "kilo-code"}, declared: "[pi, codex]", agents: []string{"codex"}, excluded: []string{"kilo-code"}},
+		{name: "disjoint", selected: []string{"kilo-code"}, declared: "[pi]", excluded: []string{"kilo-code"}},
+		{name: "declared agent outside the project", selected: []string{"codex"}, declared: "[codex, emacs]", agents: []string{"codex"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			skillFile := "---\nname: demo\n---\n"
+			if tt.declared != "" {
+				skillFile = "---\nname: demo\nagents: " + tt.declared + "\n---\n"
+			}
+			writeTestFiles(t, dir, map[string]string{"SKILL.md": skillFile})
+			cfg := ProjectConfig{
+				Agents:   []string{"codex", "kilo-code", "pi"},
+				SkillMap: map[string]SkillConfig{"demo": {Agents: tt.selected}},
+			}
+			agents, excluded, err := installAgents(cfg, "demo", plainSource(dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(agents, tt.agents) || !slices.Equal(excluded, tt.excluded) {
+				t.Errorf("installAgents = %v, %v; want %v, %v", agents, excluded, tt.agents, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestAddRespectsDeclaredAgents(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{
+		"codexonly/SKILL.md": "---\nname: codexonly\nagents: [codex]\n---\n",
+		"kiloonly/SKILL.md":  "---\nname: kiloonly\nagents: [kilo-code]\n---\n",
+	})
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex, pi]\nskillMap: {}\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	_, err := runCommand(t, addCmd(), "codexonly", "--agents", "pi")
+	if err == nil || err.Error() != "skill codexonly does not support pi (its SKILL.md lists codex)" {
+		t.Errorf("add --agents pi = %v", err)
+	}
+	_, err = runCommand(t, addCmd(), "kiloonly")
+	if err == nil || err.Error() != "skill kiloonly supports none of the project agents (codex, pi)" {
+		t.Errorf("add kiloonly = %v", err)
+	}
+
+	output, err := runCommand(t, addCmd(), "codexonly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "warning: skill codexonly does not support pi; skipping\n") {
+		t.Errorf("add printed %q", output)
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.SkillMap["codexonly"].Agents; !slices.Equal(got, []string{"codex"}) {
+		t.Errorf("recorded agents = %v, want [codex]", got)
+	}
+	if _, ok := cfg.SkillMap["kiloonly"]; ok {
+		t.Error("add registered a skill no project agent supports")
+	}
+	for rel, exists := range map[string]bool{".codex/skills/codexonly": true, ".pi/skills/codexonly": false} {
+		if _, err := os.Lstat(filepath.Join(project, rel)); (err == nil) != exists {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, exists)
+		}
+	}
+}
+
+func TestSyncSkipsUndeclaredAgents(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{"pionly/SKILL.md": "---\nagents: [pi]\n---\n"})
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex, pi]\nskillMap:\n  pionly:\n    agents: [codex, pi]\n  other:\n    source: pionly\n    agents: [codex]\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	output, err := runCommand(t, syncCmd())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "warning: skill other does not support codex; skipping\n" +
+		"warning: skill pionly does not support codex; skipping\n" +
+		"Synced pionly for pi -> " + filepath.Join(project, ".pi", "skills", "pionly") + "\n"
+	if output != want {
+		t.Errorf("sync printed:\n%s\nwant:\n%s", output, want)
+	}
+}
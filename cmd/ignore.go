@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const ignoreFileName = ".gymignore"
+
+// ignorePattern is one compiled line of gitignore syntax.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules is an ordered list of patterns. Paths are matched relative to
+// the directory the rules belong to; base is prepended for rules that live
+// above the skill, such as the repository .gymignore.
+type ignoreRules struct {
+	base     string
+	patterns []ignorePattern
+}
+
+// loadIgnoreFile reads a .gymignore file. A missing file has no rules.
+func loadIgnoreFile(path, base string) (ignoreRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ignoreRules{}, nil
+		}
+		return ignoreRules{}, fmt.Errorf("read %s: %w", path, err)
+	}
+	rules := ignoreRules{base: base}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		pattern, ok, err := compileIgnorePattern(scanner.Text())
+		if err != nil {
+			return ignoreRules{}, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		if ok {
+			rules.patterns = append(rules.patterns, pattern)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// compileGlobs compiles config globs, which use the same syntax as
+// .gymignore lines.
+func compileGlobs(globs []string) (ignoreRules, error) {
+	rules := ignoreRules{}
+	for _, glob := range globs {
+		pattern, ok, err := compileIgnorePattern(glob)
+		if err != nil {
+			return ignoreRules{}, err
+		}
+		if !ok {
+			return ignoreRules{}, fmt.Errorf("empty pattern %q", glob)
+		}
+		rules.patterns = append(rules.patterns, pattern)
+	}
+	return rules, nil
+}
+
+// compileIgnorePattern turns a gitignore line into a pattern. ok is false
+// for blank lines and comments.
+func compileIgnorePattern(line string) (ignorePattern, bool, error) {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false, nil
+	}
+	var pattern ignorePattern
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false, nil
+	}
+	// A slash anywhere but at the end anchors the pattern to its directory;
+	// otherwise it matches at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '*':
+			doubleStar := i+1 < len(line) && line[i+1] == '*' &&
+				(i == 0 || line[i-1] == '/') && (i+2 == len(line) || line[i+2] == '/')
+			switch {
+			case doubleStar && i+2 == len(line):
+				expr.WriteString(".*")
+				i++
+			case doubleStar:
+				expr.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			class, n, ok := translateClass(line[i+1:])
+			if !ok {
+				expr.WriteString(`\[`)
+				continue
+			}
+			expr.WriteString(class)
+			i += n
+		case '\\':
+			if i+1 < len(line) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return ignorePattern{}, false, fmt.Errorf("invalid pattern %q: %w", line, err)
+	}
+	pattern.re = re
+	return pattern, true, nil
+}
+
+// translateClass turns the bracket expression starting after a "[" into a
+// regexp class and returns how many bytes of s it used, including the
+// closing "]". ok is false when the class is never closed. Classes never
+// match a slash, and everything but ranges and [:name:] character classes
+// is taken literally.
+func translateClass(s string) (class string, n int, ok bool) {
+	var out strings.Builder
+	out.WriteString("[")
+	i := 0
+	if i < len(s) && (s[i] == '!' || s[i] == '^') {
+		out.WriteString("^/")
+		i++
+	}
+	for start := i; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ']' && i > start:
+			out.WriteString("]")
+			return out.String(), i + 1, true
+		case c == '[' && strings.HasPrefix(s[i:], "[:"):
+			end := strings.Index(s[i+2:], ":]")
+			if end < 0 {
+				out.WriteString(`\[`)
+				continue
+			}
+			out.WriteString(s[i : i+end+4])
+			i += end + 3
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] == '-' {
+				out.WriteString(`\-`)
+			} else {
+				out.WriteString(regexp.QuoteMeta(s[i : i+1]))
+			}
+		case c == '-':
+			out.WriteString("-")
+		default:
+			out.WriteString(regexp.QuoteMeta(s[i : i+1]))
+		}
+	}
+	return "", 0, false
+}
+
+// match reports whether the last pattern matching rel ignores it, and
+// whether any pattern matched at all. rel uses forward slashes.
+func (r ignoreRules) match(rel string, isDir bool) (ignored, matched bool) {
+	if r.base != "" {
+		rel = r.base + "/" + rel
+	}
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.re.MatchString(rel) {
+			ignored, matched = !pattern.negate, true
+		}
+	}
+	return ignored, matched
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import "testing"
+
+func TestIgnorePatterns(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		rel      string
+		isDir    bool
+		ignored  bool
+	}{
+		{[]string{"*.log"}, "a.log", false, true},
+		{[]string{"*.log"}, "dir/sub/a.log", false, true},
+		{[]string{"*.log"}, "a.log.txt", false, false},
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "src/build", true, false},
+		{[]string{"doc/*.txt"}, "doc/a.txt", false, true},
+		{[]string{"doc/*.txt"}, "doc/sub/a.txt", false, false},
+		{[]string{"doc/*.txt"}, "x/doc/a.txt", false, false},
+		{[]string{"**/foo"}, "foo", false, true},
+		{[]string{"**/foo"}, "a/b/foo", false, true},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"a/**/b"}, "a/xb", false, false},
+		{[]string{"abc/**"}, "abc/x/y", false, true},
+		{[]string{"abc/**"}, "abc", true, false},
+		{[]string{"build/"}, "build", true, true},
+		{[]string{"build/"}, "build", false, false},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{[]string{"*.log", "!keep.log"}, "drop.log", false, true},
+		{[]string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{[]string{"a*b"}, "a/b", false, false},
+		{[]string{"foo?"}, "foo1", false, true},
+		{[]string{"foo?"}, "foo12", false, false},
+		{[]string{"[abc].md"}, "b.md", false, true},
+		{[]string{"[abc].md"}, "d.md", false, false},
+		{[]string{"[a-c].md"}, "c.md", false, true},
+		{[]string{"[a-c].md"}, "-.md", false, false},
+		{[]string{"[!abc].md"}, "d.md", false, true},
+		{[]string{"[!abc].md"}, "a.md", false, false},
+		{[]string{"[^abc].md"}, "d.md", false, true},
+		{[]string{"a[!x]b"}, "a/b", false, false},
+		{[]string{"[]x].md"}, "].md", false, true},
+		{[]string{`[a\]]x`}, "]x", false, true},
+		{[]string{`[a\]]x`}, "ax", false, true},
+		{[]string{"[a^]x"}, "^x", false, true},
+		{[]string{"[.]x"}, "ax", false, false},
+		{[]string{`[\d]x`}, "dx", false, true},
+		{[]string{`[\d]x`}, "1x", false, false},
+		{[]string{"[[:digit:]]x"}, "1x", false, true},
+		{[]string{"[[:digit:]]x"}, "ax", false, false},
+		{[]string{"[ab"}, "[ab", false, true},
+		{[]string{"é*.md"}, "été.md", false, true},
+		{[]string{`\#file`}, "#file", false, true},
+		{[]string{`\!important`}, "!important", false, true},
+		{[]string{"foo  "}, "foo", false, true},
+		{[]string{`foo\ `}, "foo ", false, true},
+		{[]string{"a.b"}, "axb", false, false},
+	}
+	for _, tt := range tests {
+		rules, err := compileGlobs(tt.patterns)
+		if err != nil {
+			t.Errorf("compileGlobs(%q): %v", tt.patterns, err)
+			continue
+		}
+		if ignored, _ := rules.match(tt.rel, tt.isDir); ignored != tt.ignored {
+			t.Errorf("%q match(%q, dir=%v) = %v, want %v", tt.patterns, tt.rel, tt.isDir, ignored, tt.ignored)
+		}
+	}
+}
+
+func TestIgnorePatternSkipsCommentsAndBlanks(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/", "!"} {
+		if _, ok, err := compileIgnorePattern(line); ok || err != nil {
+			t.Errorf("compileIgnorePattern(%q) = ok %v, err %v; want skipped", line, ok, err)
+		}
+	}
+}
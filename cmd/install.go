@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	installModeCopy     = "copy"
+	installModeLink     = "link"
+	installModeHardlink = "hardlink"
+	installModeReflink  = "reflink"
+)
+
+const (
+	linkStyleRelative = "relative"
+	linkStyleAbsolute = "absolute"
+)
+
+func validateInstallSettings(mode, linkStyle string) error {
+	switch mode {
+	case "", installModeCopy, installModeLink, installModeHardlink, installModeReflink:
+	default:
+		return fmt.Errorf("unsupported mode %q (want %s, %s, %s or %s)", mode, installModeCopy, installModeLink, installModeHardlink, installModeReflink)
+	}
+	switch linkStyle {
+	case "", linkStyleRelative, linkStyleAbsolute:
+	default:
+		return fmt.Errorf("unsupported linkStyle %q (want %s or %s)", linkStyle, linkStyleRelative, linkStyleAbsolute)
+	}
+	return nil
+}
+
+// installSkill places the skill at target using the given install mode.
+// Linked skills are used as they are, so the symlink policy does not apply
+// and skills with templates or agent sections cannot be linked.
+func installSkill(src skillSource, target, mode, linkStyle string) error {
+	switch mode {
+	case installModeLink:
+		rendered, err := src.rendered()
+		if err != nil {
+			return err
+		}
+		if rendered {
+			return fmt.Errorf("skill %s is rendered on install and cannot be linked; use mode %s", filepath.Base(src.Dir), installModeCopy)
+		}
+		return linkSkillDir(src.Dir, target, linkStyle)
+	case installModeHardlink:
+		return copySkillDirWith(src, target, hardlinkPlacement)
+	case installModeReflink:
+		return copySkillDirWith(src, target, reflinkPlacement)
+	}
+	return copySkillDir(src, target)
+}
+
+// hardlinkFile links dst to the same inode as src, falling back to a copy
+// when the filesystem or device boundary does not allow hard links.
+func hardlinkFile(src, dst string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	return copyFile(src, dst, mode)
+}
+
+// reflinkFile clones src into dst with copy-on-write where the filesystem
+// supports it and copies the bytes otherwise.
+func reflinkFile(src, dst string, mode fs.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	if err := cloneFile(dstFile, srcFile); err == nil {
+		return nil
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return err
+	}
+	return nil
+}
+
+// linkSkillDir makes target a symlink to src, replacing whatever is at
+// target unless it already is the expected link.
+func linkSkillDir(src, target, linkStyle string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("stat source %s: %w", src, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("source %s is not a directory", src)
+	}
+	link, err := skillLinkText(src, target, linkStyle)
+	if err != nil {
+		return err
+	}
+	if current, err := os.Readlink(target); err == nil && current == link {
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("remove existing %s: %w", target, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("create destination %s: %w", filepath.Dir(target), err)
+	}
+	if err := os.Symlink(link, target); err != nil {
+		return fmt.Errorf("link %s -> %s: %w", target, link, err)
+	}
+	return nil
+}
+
+func skillLinkText(src, target, linkStyle string) (string, error) {
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", src, err)
+	}
+	if linkStyle == linkStyleAbsolute {
+		return absSrc, nil
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", target, err)
+	}
+	rel, err := filepath.Rel(filepath.Dir(absTarget), absSrc)
+	if err != nil {
+		return absSrc, nil
+	}
+	return rel, nil
+}
+
+// linkStatus reports how a link-mode target differs from the expected
+// symlink to src; an empty status means the link is intact.
+func linkStatus(src, target string) (string, error) {
+	info, err := os.Lstat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "project missing", nil
+		}
+		return "", err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return "not linked", nil
+	}
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "broken link", nil
+		}
+		return "", err
+	}
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !os.SameFile(srcInfo, targetInfo) {
+		return "wrong link", nil
+	}
+	return "", nil
+}
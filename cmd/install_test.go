@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkStatus(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "repo", "alpha")
+	other := filepath.Join(base, "repo", "beta")
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "alpha\n"})
+	writeTestFiles(t, other, map[string]string{"SKILL.md": "beta\n"})
+	skills := filepath.Join(base, "project", ".codex", "skills")
+	if err := os.MkdirAll(skills, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(target string) error
+		status string
+	}{
+		{"intact", func(target string) error { return linkSkillDir(src, target, linkStyleRelative) }, ""},
+		{"intact absolute", func(target string) error { return linkSkillDir(src, target, linkStyleAbsolute) }, ""},
+		{"missing", func(target string) error { return nil }, "project missing"},
+		{"copy", func(target string) error { return copySkillDir(plainSource(src), target) }, "not linked"},
+		{"broken", func(target string) error { return os.Symlink(filepath.Join(base, "gone"), target) }, "broken link"},
+		{"wrong", func(target string) error { return os.Symlink(other, target) }, "wrong link"},
+	}
+	for _, tt := range tests {
+		target := filepath.Join(skills, tt.name)
+		if err := tt.setup(target); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		status, err := linkStatus(src, target)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if status != tt.status {
+			t.Errorf("%s: status %q, want %q", tt.name, status, tt.status)
+		}
+		if tt.status == "" {
+			continue
+		}
+		// Sync repairs every state into an intact link.
+		if err := linkSkillDir(src, target, linkStyleRelative); err != nil {
+			t.Fatalf("%s: relink: %v", tt.name, err)
+		}
+		if status, err := linkStatus(src, target); err != nil || status != "" {
+			t.Errorf("%s: after relink status %q, err %v", tt.name, status, err)
+		}
+	}
+}
+
+func TestLinkSkillDirStyles(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "repo", "alpha")
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "alpha\n"})
+	target := filepath.Join(base, "project", ".codex", "skills", "alpha")
+
+	if err := linkSkillDir(src, target, linkStyleRelative); err != nil {
+		t.Fatal(err)
+	}
+	if link, _ := os.Readlink(target); link != filepath.Join("..", "..", "..", "repo", "alpha") {
+		t.Errorf("relative link = %q", link)
+	}
+	if err := linkSkillDir(src, target, linkStyleAbsolute); err != nil {
+		t.Fatal(err)
+	}
+	if link, _ := os.Readlink(target); link != src {
+		t.Errorf("absolute link = %q, want %q", link, src)
+	}
+}
+
+func TestTargetCheckReportsUnexpectedLink(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	src := filepath.Join(base, "repo", "alpha")
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "alpha\n"})
+	target := filepath.Join(base, "project", ".codex", "skills", "alpha")
+	if err := linkSkillDir(src, target, linkStyleRelative); err != nil {
+		t.Fatal(err)
+	}
+	check := &targetCheck{skill: "alpha", agent: "codex", src: plainSource(src), target: target, mode: installModeCopy}
+	if err := check.run(); err != nil {
+		t.Fatal(err)
+	}
+	if !check.drift || check.problem != "unexpected link" {
+		t.Errorf("copy mode over a link: drift %v, problem %q", check.drift, check.problem)
+	}
+
+	check = &targetCheck{skill: "alpha", agent: "codex", src: plainSource(src), target: target, mode: installModeLink}
+	if err := check.run(); err != nil {
+		t.Fatal(err)
+	}
+	if check.drift || check.problem != "" {
+		t.Errorf("link mode over an intact link: drift %v, problem %q", check.drift, check.problem)
+	}
+}
+
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+func TestHardlinkAndReflinkFile(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "src.md")
+	if err := os.WriteFile(src, []byte("data\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	linked := filepath.Join(base, "a", "linked.md")
+	if err := hardlinkFile(src, linked, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if !sameFile(t, src, linked) {
+		t.Error("hardlinkFile did not share the inode")
+	}
+
+	// Linking onto an existing file fails, so hardlinkFile falls back to a copy.
+	existing := filepath.Join(base, "existing.md")
+	if err := os.WriteFile(existing, []byte("old content\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := hardlinkFile(src, existing, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "data\n" || sameFile(t, src, existing) {
+		t.Errorf("fallback copy: content %q, shared %v", data, sameFile(t, src, existing))
+	}
+
+	cloned := filepath.Join(base, "b", "cloned.md")
+	if err := reflinkFile(src, cloned, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(cloned); string(data) != "data\n" || sameFile(t, src, cloned) {
+		t.Errorf("reflink: content %q, shared %v", data, sameFile(t, src, cloned))
+	}
+}
+
+func TestSyncSwitchesBetweenLinkedAndCopiedFiles(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	src := filepath.Join(base, "repo", "alpha")
+	target := filepath.Join(base, "project", "alpha")
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "alpha\n"})
+	srcFile, targetFile := filepath.Join(src, "SKILL.md"), filepath.Join(target, "SKILL.md")
+
+	if err := copySkillDir(plainSource(src), target); err != nil {
+		t.Fatal(err)
+	}
+	if err := copySkillDirWith(plainSource(src), target, hardlinkPlacement); err != nil {
+		t.Fatal(err)
+	}
+	if !sameFile(t, srcFile, targetFile) {
+		t.Error("hardlink sync kept an equal plain copy")
+	}
+
+	if err := copySkillDir(plainSource(src), target); err != nil {
+		t.Fatal(err)
+	}
+	if sameFile(t, srcFile, targetFile) {
+		t.Fatal("copy sync kept the hard link")
+	}
+	if err := os.WriteFile(targetFile, []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(srcFile); string(data) != "alpha\n" {
+		t.Errorf("project edit reached the repository: %q", data)
+	}
+}
+
+func TestDriftReportsHardlinkWriteThroughOnly(t *testing.T) {
+	for _, mode := range []string{installModeHardlink, installModeReflink} {
+		t.Run(mode, func(t *testing.T) {
+			base := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+			repo := skillRepository{Dir: filepath.Join(base, "repo"), Symlinks: symlinkPreserve}
+			projectRoot := filepath.Join(base, "project")
+			writeTestFiles(t, repo.Dir, map[string]string{"alpha/SKILL.md": "alpha\n"})
+			cfg := ProjectConfig{Agents: []string{"codex"}, Mode: mode, SkillMap: map[string]SkillConfig{"alpha": {}}}
+			if err := os.MkdirAll(projectRoot, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeProjectConfig(projectRoot, cfg); err != nil {
+				t.Fatal(err)
+			}
+			if err := syncSkills(io.Discard, projectRoot, repo, cfg, []string{"alpha"}, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			// An in-place edit of the installed file.
+			target := filepath.Join(projectRoot, ".codex", "skills", "alpha", "SKILL.md")
+			file, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.WriteString("more\n")
+			file.Close()
+
+			drifted, err := projectDriftSkills(projectRoot, repo, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(drifted) != 1 {
+				t.Fatalf("drifted = %+v, want alpha", drifted)
+			}
+			hardlinked := sameFile(t, target, filepath.Join(repo.Dir, "alpha", "SKILL.md"))
+			if got, want := drifted[0].Status == "repo changed since install", mode == installModeHardlink && hardlinked; got != want {
+				t.Errorf("status %q for %s (hard-linked %v)", drifted[0].Status, mode, hardlinked)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+type lintIssue struct {
+	skill   string
+	path    string
+	message string
+	warning bool
+}
+
+func lintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint [skill-name...]",
+		Short: "Check repository skills for problems before they are installed",
+		Long: "Check repository skills for problems before they are installed.\n\n" +
+			"Without arguments every skill in the repository is checked. Symlinks are\n" +
+			"checked against the repository's symlinks policy. Exits with an error if\n" +
+			"any skill has errors; warnings are only printed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			repo := globalCfg.repository()
+			skillNames := args
+			if len(skillNames) == 0 {
+				skillNames, err = repositorySkills(repo.Dir)
+				if err != nil {
+					return err
+				}
+			}
+
+			errorCount := 0
+			failed := 0
+			for _, skillName := range skillNames {
+				issues, err := lintSkill(repo, skillName)
+				if err != nil {
+					return err
+				}
+				skillFailed := false
+				for _, issue := range issues {
+					level := "error"
+					if issue.warning {
+						level = "warning"
+					} else {
+						errorCount++
+						skillFailed = true
+					}
+					location := issue.skill
+					if issue.path != "" {
+						location += "/" + filepath.ToSlash(issue.path)
+					}
+					fmt.Fprintf(os.Stdout, "%s: %s: %s\n", location, level, issue.message)
+				}
+				if skillFailed {
+					failed++
+				}
+			}
+			if errorCount > 0 {
+				return fmt.Errorf("found %d errors in %d of %d skills", errorCount, failed, len(skillNames))
+			}
+			fmt.Fprintf(os.Stdout, "Checked %d skills\n", len(skillNames))
+			return nil
+		},
+	}
+}
+
+// lintSkill collects the problems of one repository skill.
+func lintSkill(repo skillRepository, skillName string) ([]lintIssue, error) {
+	if err := validateSkillName(skillName); err != nil {
+		return []lintIssue{{skill: skillName, message: err.Error()}}, nil
+	}
+	src := repo.skill(skillName)
+	info, err := os.Stat(src.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []lintIssue{{skill: skillName, message: "not found in repository"}}, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []lintIssue{{skill: skillName, message: "not a directory"}}, nil
+	}
+
+	issues := make([]lintIssue, 0)
+	hasSkillFile, err := dirHasSkillFile(src.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if !hasSkillFile {
+		issues = append(issues, lintIssue{skill: skillName, message: "missing SKILL.md"})
+	}
+	meta, err := readSkillMetadata(src.Dir)
+	if err != nil {
+		issues = append(issues, lintIssue{skill: skillName, path: "SKILL.md", message: err.Error()})
+	}
+	for _, agent := range meta.Agents {
+		if _, ok := supportedAgents[agent]; !ok {
+			issues = append(issues, lintIssue{skill: skillName, path: "SKILL.md", message: fmt.Sprintf("unsupported agent %q in frontmatter", agent)})
+		}
+	}
+	linkIssues, err := lintSymlinks(skillName, src)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, linkIssues...)
+	// Walking the source the way sync does catches what the per-link checks
+	// cannot see, such as dereferenced links that form a cycle. The file
+	// checks below walk it the same way, so they only run once it succeeds.
+	if err := src.walk(func(entry skillEntry) error { return nil }); err != nil {
+		if len(linkIssues) == 0 {
+			issues = append(issues, lintIssue{skill: skillName, message: err.Error()})
+		}
+		return issues, nil
+	}
+	templateIssues, err := lintTemplates(skillName, src, meta)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, templateIssues...)
+	sectionIssues, err := lintAgentSections(skillName, src, meta)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, sectionIssues...)
+	return issues, nil
+}
+
+// lintTemplates checks the templates a skill declares. Templates are
+// rendered with the declared variables, required ones left empty, so a
+// reference to an undeclared variable shows up as a warning: it may still
+// be set project-wide in .skills.yaml.
+func lintTemplates(skillName string, src skillSource, meta skillMetadata) ([]lintIssue, error) {
+	issues := make([]lintIssue, 0)
+	for name := range meta.Vars {
+		if !isIdentifier(name) {
+			issues = append(issues, lintIssue{skill: skillName, path: "SKILL.md", message: fmt.Sprintf("invalid template variable name %q", name)})
+		}
+	}
+	if len(meta.Templates) == 0 {
+		return issues, nil
+	}
+	files, err := compileGlobs(meta.Templates)
+	if err != nil {
+		return append(issues, lintIssue{skill: skillName, path: "SKILL.md", message: fmt.Sprintf("templates: %v", err)}), nil
+	}
+	vars := map[string]string{}
+	for name, value := range meta.Vars {
+		if value != nil {
+			vars[name] = *value
+		} else {
+			vars[name] = ""
+		}
+	}
+	matched := false
+	err = src.walk(func(entry skillEntry) error {
+		if !entry.Info.Mode().IsRegular() {
+			return nil
+		}
+		rel := entry.Rel
+		if selected, _ := files.match(filepath.ToSlash(rel), false); !selected {
+			return nil
+		}
+		matched = true
+		data, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := template.New(rel).Parse(string(data)); err != nil {
+			issues = append(issues, lintIssue{skill: skillName, path: rel, message: err.Error()})
+		} else if _, err := executeTemplate(rel, data, vars); err != nil {
+			issues = append(issues, lintIssue{skill: skillName, path: rel, message: err.Error(), warning: true})
+		}
+		return nil
+	})
+	if !matched {
+		issues = append(issues, lintIssue{skill: skillName, path: "SKILL.md", message: "templates match no files", warning: true})
+	}
+	return issues, err
+}
+
+// lintAgentSections checks the agent sections of a skill's markdown files.
+// Sections for agents the frontmatter does not declare are never installed
+// and are reported as warnings.
+func lintAgentSections(skillName string, src skillSource, meta skillMetadata) ([]lintIssue, error) {
+	issues := make([]lintIssue, 0)
+	err := src.walk(func(entry skillEntry) error {
+		if !entry.Info.Mode().IsRegular() || !isMarkdownFile(entry.Rel) {
+			return nil
+		}
+		data, err := os.ReadFile(entry.Path)
+		if err != nil || !containsAgentSections(data) {
+			return err
+		}
+		rel := entry.Rel
+		_, agents, err := resolveAgentSections(data, "")
+		if err != nil {
+			issues = append(issues, lintIssue{skill: skillName, path: rel, message: err.Error()})
+			return nil
+		}
+		for _, agent := range agents {
+			if _, ok := supportedAgents[agent]; !ok {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: fmt.Sprintf("unsupported agent %q in gym:if", agent)})
+			} else if len(meta.Agents) > 0 && !slices.Contains(meta.Agents, agent) {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: fmt.Sprintf("gym:if names %s, which SKILL.md does not declare", agent), warning: true})
+			}
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// lintSymlinks checks every symlink in a skill against the repository's
+// symlink policy. Links that would break in projects are errors when the
+// policy rejects or dereferences them and warnings when they are preserved.
+func lintSymlinks(skillName string, src skillSource) ([]lintIssue, error) {
+	issues := make([]lintIssue, 0)
+	err := filepath.WalkDir(src.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(src.Dir, path)
+		if err != nil {
+			return err
+		}
+		escapes, err := symlinkEscapes(src.Dir, path)
+		if err != nil {
+			return err
+		}
+		_, statErr := os.Stat(path)
+		dangling := os.IsNotExist(statErr)
+		switch src.Symlinks {
+		case symlinkReject:
+			if escapes {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: "symlink escapes the skill directory"})
+			} else if dangling {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: "symlink is dangling"})
+			}
+		case symlinkDereference:
+			if dangling {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: "symlink is dangling"})
+			}
+		default:
+			if dangling {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: "symlink is dangling"})
+			} else if escapes {
+				issues = append(issues, lintIssue{skill: skillName, path: rel, message: "symlink points outside the skill and will not resolve in projects", warning: true})
+			}
+		}
+		return nil
+	})
+	return issues, err
+}
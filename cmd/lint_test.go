@@ -0,0 +1,38 @@
+package cmd
+
+import "testing"
+
+func TestLintSkipsIgnoredFiles(t *testing.T) {
+	repoDir := t.TempDir()
+	writeTestFiles(t, repoDir, map[string]string{
+		".gymignore":              "drafts/\n",
+		"demo/SKILL.md":           "---\nname: demo\ntemplates: [\"*.tmpl\"]\n---\n",
+		"demo/.gymignore":         "*.bak.md\n",
+		"demo/main.tmpl":          "fine\n",
+		"demo/drafts/broken.tmpl": "{{ .x\n",
+		"demo/drafts/open.md":     "<!-- gym:if agent=codex -->\n",
+		"demo/old.bak.md":         "<!-- gym:else -->\n",
+	})
+	repo := skillRepository{Dir: repoDir, Symlinks: symlinkPreserve}
+	issues, err := lintSkill(repo, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("lintSkill reported ignored files: %+v", issues)
+	}
+
+	src := repo.skill("demo")
+	writeTestFiles(t, repoDir, map[string]string{"demo/SKILL.md": "---\nname: demo\n---\n"})
+	rendered, err := src.rendered()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered {
+		t.Error("rendered() counted agent sections in ignored files")
+	}
+	writeTestFiles(t, repoDir, map[string]string{"demo/guide.md": "<!-- gym:if agent=codex -->\nx\n<!-- gym:end -->\n"})
+	if rendered, err = src.rendered(); err != nil || !rendered {
+		t.Errorf("rendered() = %v, %v; want true", rendered, err)
+	}
+}
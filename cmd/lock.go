@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const projectLockName = ".skills.lock"
+
+// ProjectLock records the state of each skill at the time it was last
+// installed into the project, so later commands can tell repository changes
+// apart from local edits.
+type ProjectLock struct {
+	Skills map[string]SkillLock `yaml:"skills"`
+}
+
+type SkillLock struct {
+	Source  string            `yaml:"source"`
+	Targets map[string]string `yaml:"targets,omitempty"`
+}
+
+func loadProjectLock(projectRoot string) (ProjectLock, error) {
+	path := filepath.Join(projectRoot, projectLockName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ProjectLock{Skills: map[string]SkillLock{}}, nil
+		}
+		return ProjectLock{}, fmt.Errorf("read project lock %s: %w", path, err)
+	}
+	var lock ProjectLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return ProjectLock{}, fmt.Errorf("parse project lock %s: %w", path, err)
+	}
+	if lock.Skills == nil {
+		lock.Skills = map[string]SkillLock{}
+	}
+	return lock, nil
+}
+
+func writeProjectLock(projectRoot string, lock ProjectLock) error {
+	path := filepath.Join(projectRoot, projectLockName)
+	if len(lock.Skills) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove project lock %s: %w", path, err)
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("marshal project lock: %w", err)
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// recordInstall stores the repository and target hashes for a skill that
+// was just installed. targets maps agent names to installed paths.
+func recordInstall(lock *ProjectLock, skillName string, skillSrc skillSource, targets map[string]string) error {
+	if lock.Skills == nil {
+		lock.Skills = map[string]SkillLock{}
+	}
+	entry, err := lockEntry(lock.Skills[skillName], skillSrc, targets)
+	if err != nil {
+		return err
+	}
+	lock.Skills[skillName] = entry
+	return nil
+}
+
+// lockEntry hashes an installed skill. Target hashes from previous are kept
+// for agents not in targets as long as the repository skill is unchanged;
+// after a repository change they no longer describe an up-to-date install.
+func lockEntry(previous SkillLock, skillSrc skillSource, targets map[string]string) (SkillLock, error) {
+	hasher := newDirHasher()
+	sourceHash, err := hasher.hashSource(skillSrc)
+	if err != nil {
+		return SkillLock{}, fmt.Errorf("hash skill %s: %w", skillSrc.Dir, err)
+	}
+	entry := SkillLock{Source: sourceHash, Targets: map[string]string{}}
+	if previous.Source == sourceHash {
+		for agent, hash := range previous.Targets {
+			entry.Targets[agent] = hash
+		}
+	}
+	for agent, target := range targets {
+		targetHash, err := hasher.hashSource(plainSource(target))
+		if err != nil {
+			return SkillLock{}, fmt.Errorf("hash skill %s: %w", target, err)
+		}
+		entry.Targets[agent] = targetHash
+	}
+	return entry, nil
+}
+
+// dirHasher hashes directory trees, remembering file hashes by inode so
+// hard-linked copies of a skill are only read once.
+type dirHasher struct {
+	files map[fileID]string
+}
+
+func newDirHasher() *dirHasher {
+	return &dirHasher{files: map[fileID]string{}}
+}
+
+// hashDir returns a digest of a directory tree covering relative paths,
+// file permissions, file contents and symlink targets.
+func hashDir(dir string) (string, error) {
+	return newDirHasher().hashSource(plainSource(dir))
+}
+
+// hashSource hashes a skill source as it would be installed, so a source
+// and a faithful copy of it hash the same. Linked skills are hashed
+// through the link so they match their source.
+func (h *dirHasher) hashSource(src skillSource) (string, error) {
+	digest := sha256.New()
+	if err := src.walk(func(entry skillEntry) error {
+		rel := filepath.ToSlash(entry.Rel)
+		mode := entry.Info.Mode()
+		switch {
+		case mode&os.ModeSymlink != 0:
+			link, err := os.Readlink(entry.Path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(digest, "l %s %s\n", rel, link)
+		case mode.IsDir():
+			fmt.Fprintf(digest, "d %s\n", rel)
+		case entry.Content != nil:
+			sum := sha256.Sum256(entry.Content)
+			fmt.Fprintf(digest, "f %s %o %s\n", rel, mode.Perm(), hex.EncodeToString(sum[:]))
+		default:
+			fileHash, err := h.hashFile(entry.Path, entry.Info)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(digest, "f %s %o %s\n", rel, mode.Perm(), fileHash)
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+func (h *dirHasher) hashFile(path string, info fs.FileInfo) (string, error) {
+	id, ok := fileIdentity(info)
+	if ok {
+		if hash, found := h.files[id]; found {
+			return hash, nil
+		}
+	}
+	hash, err := sharedHashCache().hash(path, info)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		h.files[id] = hash
+	}
+	return hash, nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
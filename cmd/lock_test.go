@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLockEntryKeepsTargetsOnlyForUnchangedSource(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	src := filepath.Join(base, "repo", "alpha")
+	target := filepath.Join(base, "project", ".codex", "skills", "alpha")
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "v1\n"})
+	writeTestFiles(t, target, map[string]string{"SKILL.md": "v1\n"})
+
+	first, err := lockEntry(SkillLock{}, plainSource(src), map[string]string{"codex": target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Targets["pi"] = "sha256:pi"
+
+	same, err := lockEntry(first, plainSource(src), map[string]string{"codex": target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Targets["pi"] != "sha256:pi" {
+		t.Errorf("unchanged source dropped the pi target: %v", same.Targets)
+	}
+
+	writeTestFiles(t, src, map[string]string{"SKILL.md": "v2\n"})
+	writeTestFiles(t, target, map[string]string{"SKILL.md": "v2\n"})
+	changed, err := lockEntry(first, plainSource(src), map[string]string{"codex": target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Source == first.Source {
+		t.Fatal("source hash did not change")
+	}
+	if _, ok := changed.Targets["pi"]; ok {
+		t.Errorf("changed source kept the stale pi target: %v", changed.Targets)
+	}
+	if changed.Targets["codex"] == "" {
+		t.Errorf("codex target not recorded: %v", changed.Targets)
+	}
+}
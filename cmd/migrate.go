@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	projectConfigVersion = 1
+	globalConfigVersion  = 1
+)
+
+// configMigration upgrades a config document from schema version from to
+// from+1. Migrations edit the YAML node tree in place so comments survive.
+type configMigration struct {
+	from        int
+	description string
+	apply       func(root *yaml.Node) error
+}
+
+// Files written before versioning have no version key and count as
+// version 0; their shape is identical to version 1.
+var projectMigrations = []configMigration{
+	{from: 0, description: "record the schema version", apply: func(root *yaml.Node) error { return nil }},
+}
+
+var globalMigrations = []configMigration{
+	{from: 0, description: "record the schema version", apply: func(root *yaml.Node) error { return nil }},
+}
+
+// migrateConfigNode applies the migrations a document needs to reach
+// version and returns the version it started at. The version key is left
+// alone, so configs read for a command keep the version they have on disk;
+// migrateConfigFile records the new one.
+func migrateConfigNode(doc *yaml.Node, version int, migrations []configMigration) (int, error) {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return version, nil
+	}
+	root := doc.Content[0]
+	start := 0
+	if node := mappingValue(root, "version"); node != nil {
+		parsed, err := strconv.Atoi(node.Value)
+		if err != nil || parsed < 0 {
+			return 0, fmt.Errorf("line %d: version must be a non-negative integer", node.Line)
+		}
+		start = parsed
+	}
+	if start > version {
+		return start, fmt.Errorf("schema version %d is newer than this gym supports (%d); upgrade gym", start, version)
+	}
+	for _, migration := range migrations {
+		if migration.from < start {
+			continue
+		}
+		if err := migration.apply(root); err != nil {
+			return start, fmt.Errorf("migrate from version %d: %w", migration.from, err)
+		}
+	}
+	return start, nil
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets a scalar value, adding the key at the top of the
+// mapping when it is missing.
+func setMappingValue(mapping *yaml.Node, key, value string) {
+	if node := mappingValue(mapping, key); node != nil {
+		node.Kind, node.Tag, node.Value, node.Content = yaml.ScalarNode, "", value, nil
+		return
+	}
+	pair := []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: key},
+		{Kind: yaml.ScalarNode, Value: value},
+	}
+	// Keep a leading file comment at the top of the document.
+	if len(mapping.Content) > 0 {
+		pair[0].HeadComment = mapping.Content[0].HeadComment
+		mapping.Content[0].HeadComment = ""
+	}
+	mapping.Content = append(pair, mapping.Content...)
+}
+
+func migrateCmd() *cobra.Command {
+	var global bool
+	var workspace bool
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade config files to the current schema version",
+		Long: "Upgrade config files to the current schema version.\n\n" +
+			"Without flags the .skills.yaml of the current project is upgraded. Older\n" +
+			"files keep working without migrating; migrating records the version so\n" +
+			"newer gym releases can evolve the format safely. --global also moves a\n" +
+			"~/.gym.yaml left by older releases to the gym config directory.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if global && workspace {
+				return fmt.Errorf("--global and --workspace cannot be combined")
+			}
+			if global {
+				return migrateGlobalConfig(dryRun)
+			}
+			projects := make([]string, 0)
+			if workspace {
+				globalCfg, err := loadGlobalConfig()
+				if err != nil {
+					return err
+				}
+				projects = globalCfg.Projects
+			} else {
+				projectRoot, err := findProjectRoot()
+				if err != nil {
+					return err
+				}
+				projects = append(projects, projectRoot)
+			}
+			failed := 0
+			for _, project := range projects {
+				if err := migrateProjectConfig(project, dryRun); err != nil {
+					fmt.Fprintf(os.Stdout, "%s: error: %v\n", project, err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("migration failed for %d of %d projects", failed, len(projects))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&global, "global", false, "upgrade the global config instead of the project config")
+	cmd.Flags().BoolVar(&workspace, "workspace", false, "upgrade every registered project")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be upgraded without writing")
+	return cmd
+}
+
+func migrateProjectConfig(projectRoot string, dryRun bool) error {
+	stateLock, err := lockProject(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer stateLock.release()
+	path := filepath.Join(projectRoot, projectConfigName)
+	return migrateConfigFile(path, &ProjectConfig{}, projectConfigVersion, projectMigrations, dryRun)
+}
+
+func migrateGlobalConfig(dryRun bool) error {
+	configLock, err := lockGlobalConfig()
+	if err != nil {
+		return err
+	}
+	defer configLock.release()
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	legacy, err := legacyGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	if path == legacy {
+		target, err := defaultGlobalConfigPath()
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Fprintf(os.Stdout, "Would move %s to %s\n", legacy, target)
+		} else {
+			if err := moveLegacyGlobalConfig(legacy, target); err != nil {
+				return err
+			}
+			path = target
+		}
+	}
+	return migrateConfigFile(path, &GlobalConfig{}, globalConfigVersion, globalMigrations, dryRun)
+}
+
+// migrateConfigFile upgrades one file in place. The result is decoded
+// strictly before it is written, so a migration never produces a file gym
+// cannot read.
+func migrateConfigFile(path string, out interface{}, version int, migrations []configMigration, dryRun bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	// Errors are reported against the file as it is on disk, before the
+	// migration shifts its lines.
+	if err := decodeConfig(data, out, version, migrations); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	start, err := migrateConfigNode(&doc, version, migrations)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if start == version {
+		fmt.Fprintf(os.Stdout, "%s is up to date (version %d)\n", path, version)
+		return nil
+	}
+	setMappingValue(doc.Content[0], "version", strconv.Itoa(version))
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent(data))
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("marshal %s: %w", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("marshal %s: %w", path, err)
+	}
+	if err := decodeConfig(buf.Bytes(), out, version, migrations); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if dryRun {
+		fmt.Fprintf(os.Stdout, "Would migrate %s from version %d to %d\n", path, start, version)
+		return nil
+	}
+	if err := writeFileAtomic(path, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Migrated %s from version %d to %d\n", path, start, version)
+	for _, migration := range migrations {
+		if migration.from >= start {
+			fmt.Fprintf(os.Stdout, "  %d -> %d: %s\n", migration.from, migration.from+1, migration.description)
+		}
+	}
+	return nil
+}
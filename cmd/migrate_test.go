@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateProjectConfig(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	path := filepath.Join(project, projectConfigName)
+	old := "# project skills\nagents:\n  - codex # main agent\nskillMap: {}\n"
+	writeTestFiles(t, project, map[string]string{projectConfigName: old})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	output, err := runCommand(t, migrateCmd(), "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Would migrate "+path+" from version 0 to 1\n" {
+		t.Errorf("dry run output = %q", output)
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Errorf("dry run changed the file:\n%s", data)
+	}
+
+	output, err = runCommand(t, migrateCmd())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Migrated "+path+" from version 0 to 1\n  0 -> 1: record the schema version\n" {
+		t.Errorf("migrate output = %q", output)
+	}
+	want := "# project skills\nversion: 1\nagents:\n  - codex # main agent\nskillMap: {}\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("migrated file:\n%s\nwant:\n%s", data, want)
+	}
+
+	output, err = runCommand(t, migrateCmd())
+	if err != nil || output != path+" is up to date (version 1)\n" {
+		t.Errorf("second migrate = %q, %v", output, err)
+	}
+}
+
+func TestMigrateRefusesBrokenConfig(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	broken := "agents: [codex]\nskillMap:\n  alpha:\n    modee: link\n"
+	writeTestFiles(t, project, map[string]string{projectConfigName: broken})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	output, err := runCommand(t, migrateCmd())
+	if err == nil || !strings.Contains(output, `line 4: unknown key "modee"`) {
+		t.Errorf("migrate = %v, output %q", err, output)
+	}
+	if data, _ := os.ReadFile(filepath.Join(project, projectConfigName)); string(data) != broken {
+		t.Errorf("migrate changed a broken file:\n%s", data)
+	}
+}
+
+func TestWritesOnlyStampVersionOnCreate(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents:\n  - codex\nskillMap: {}\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	if output, err := runCommand(t, addCmd(), "alpha"); err != nil {
+		t.Fatalf("add: %v\n%s", err, output)
+	}
+	data, err := os.ReadFile(filepath.Join(project, projectConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "agents:\n  - codex\nskillMap:\n  alpha: {}\n"; string(data) != want {
+		t.Errorf("add wrote:\n%s\nwant:\n%s", data, want)
+	}
+
+	fresh := filepath.Join(base, "fresh")
+	setupTestProject(t, fresh)
+	cfg, err := readProjectConfigFile(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != projectConfigVersion {
+		t.Errorf("new config version = %d, want %d", cfg.Version, projectConfigVersion)
+	}
+}
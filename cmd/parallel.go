@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+)
+
+var errTaskSkipped = errors.New("task skipped")
+
+func addJobsFlag(cmd *cobra.Command, jobs *int) {
+	cmd.Flags().IntVarP(jobs, "jobs", "j", runtime.NumCPU(), "number of skills and agents processed in parallel")
+}
+
+func validateJobs(jobs int) error {
+	if jobs < 1 {
+		return errors.New("--jobs must be at least 1")
+	}
+	return nil
+}
+
+// runOrdered runs work for indexes 0..n-1 on at most jobs goroutines and
+// calls emit with each result in index order from the calling goroutine,
+// so output stays deterministic. Once emit returns an error, pending work
+// is skipped and that error is returned.
+func runOrdered(n, jobs int, work func(i int) error, emit func(i int, err error) error) error {
+	if n == 0 {
+		return nil
+	}
+	jobs = max(1, min(jobs, n))
+
+	results := make([]chan error, n)
+	for i := range results {
+		results[i] = make(chan error, 1)
+	}
+	var stopped atomic.Bool
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if stopped.Load() {
+					results[i] <- errTaskSkipped
+					continue
+				}
+				results[i] <- work(i)
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	var firstErr error
+	for i := 0; i < n; i++ {
+		err := <-results[i]
+		if firstErr != nil {
+			continue
+		}
+		if err := emit(i, err); err != nil {
+			firstErr = err
+			stopped.Store(true)
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunOrderedEmitsInIndexOrder(t *testing.T) {
+	const n = 50
+	var emitted []int
+	err := runOrdered(n, 8, func(i int) error {
+		// Later indexes finish first, so results arrive out of order.
+		time.Sleep(time.Duration(n-i) * 100 * time.Microsecond)
+		if i%2 == 1 {
+			return fmt.Errorf("task %d", i)
+		}
+		return nil
+	}, func(i int, err error) error {
+		if (i%2 == 1) != (err != nil) {
+			t.Errorf("emit(%d) got error %v", i, err)
+		}
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runOrdered: %v", err)
+	}
+	if len(emitted) != n {
+		t.Fatalf("emitted %d results, want %d", len(emitted), n)
+	}
+	for i, got := range emitted {
+		if got != i {
+			t.Fatalf("emitted[%d] = %d, want %d", i, got, i)
+		}
+	}
+}
+
+func TestRunOrderedStopsAfterEmitError(t *testing.T) {
+	const n = 100
+	stop := errors.New("stop")
+	release := make(chan struct{})
+	var calls atomic.Int32
+	var emitted []int
+	err := runOrdered(n, 2, func(i int) error {
+		calls.Add(1)
+		if i >= 2 {
+			<-release
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}, func(i int, err error) error {
+		emitted = append(emitted, i)
+		if i == 1 {
+			close(release)
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("runOrdered returned %v, want %v", err, stop)
+	}
+	if len(emitted) != 2 {
+		t.Errorf("emit called for %v, want only 0 and 1", emitted)
+	}
+	if got := calls.Load(); got >= n {
+		t.Errorf("work ran for all %d tasks after the error", got)
+	}
+}
+
+func TestRunOrderedEmpty(t *testing.T) {
+	err := runOrdered(0, 4, func(int) error {
+		t.Fatal("work called")
+		return nil
+	}, func(int, error) error {
+		t.Fatal("emit called")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runOrdered: %v", err)
+	}
+}
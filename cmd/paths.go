@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	appDirName           = "gym"
+	globalConfigName     = "config.yaml"
+	legacyGlobalConfig   = ".gym.yaml"
+	configBackupsDirName = "backups"
+)
+
+// xdgDir returns the gym directory below the XDG base directory named by
+// env, or below fallback in the home directory when env is unset. Relative
+// values are ignored, as the XDG spec requires.
+func xdgDir(env string, fallback ...string) (string, error) {
+	if base := os.Getenv(env); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(append(append([]string{home}, fallback...), appDirName)...), nil
+}
+
+func configDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+func stateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// cacheDir honours XDG_CACHE_HOME everywhere and otherwise uses the
+// platform cache directory.
+func cacheDir() (string, error) {
+	if base := os.Getenv("XDG_CACHE_HOME"); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appDirName), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve cache directory: %w", err)
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// globalConfigPath returns the global config file: GYM_CONFIG when set,
+// otherwise config.yaml in the gym config directory. A ~/.gym.yaml left by
+// older releases is used until gym migrate --global moves it.
+func globalConfigPath() (string, error) {
+	if path := os.Getenv(envConfig); path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("resolve %s: %w", envConfig, err)
+		}
+		return abs, nil
+	}
+	path, err := defaultGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(path); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return path, nil
+	}
+	legacy, err := legacyGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(legacy); err == nil {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+func defaultGlobalConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, globalConfigName), nil
+}
+
+func legacyGlobalConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, legacyGlobalConfig), nil
+}
+
+// moveLegacyGlobalConfig moves the global config from legacy to path and
+// keeps a copy in the state directory. A legacy file that is a symlink,
+// typically into a dotfiles directory, stays in place and path is linked
+// to the file it points to. The caller holds the global config lock; the
+// lock of path is taken as well so no process starts using it early.
+func moveLegacyGlobalConfig(legacy, path string) error {
+	info, err := os.Lstat(legacy)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	pathLock, err := acquireFileLock(path + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer pathLock.release()
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s already exists; remove %s or %s", path, legacy, path)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(legacy)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", legacy, err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			return fmt.Errorf("link %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stdout, "Linked %s to %s; %s is a symlink and was left in place\n", path, target, legacy)
+		return nil
+	}
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", legacy, err)
+	}
+	if err := writeFileAtomic(path, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	backup, err := backupConfigFile(strings.TrimPrefix(legacyGlobalConfig, "."), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(legacy); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Moved %s to %s (backup in %s)\n", legacy, path, backup)
+	return nil
+}
+
+// backupConfigFile stores a timestamped copy of a config file in the
+// state directory and returns its path.
+func backupConfigFile(name string, data []byte) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, configBackupsDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, name+"."+time.Now().Format("20060102-150405"))
+	if err := writeFileAtomic(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestXDGDirs(t *testing.T) {
+	base := setupTestHome(t)
+	home := filepath.Join(base, "home")
+	tests := []struct {
+		name string
+		env  string
+		dir  func() (string, error)
+		set  string
+		want string
+	}{
+		{"config", "XDG_CONFIG_HOME", configDir, filepath.Join(base, "xdg"), filepath.Join(base, "xdg", "gym")},
+		{"config unset", "XDG_CONFIG_HOME", configDir, "", filepath.Join(home, ".config", "gym")},
+		{"config relative", "XDG_CONFIG_HOME", configDir, "relative", filepath.Join(home, ".config", "gym")},
+		{"state", "XDG_STATE_HOME", stateDir, filepath.Join(base, "xdg"), filepath.Join(base, "xdg", "gym")},
+		{"state unset", "XDG_STATE_HOME", stateDir, "", filepath.Join(home, ".local", "state", "gym")},
+		{"cache", "XDG_CACHE_HOME", cacheDir, filepath.Join(base, "xdg"), filepath.Join(base, "xdg", "gym")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.set)
+			dir, err := tt.dir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dir != tt.want {
+				t.Errorf("dir = %s, want %s", dir, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobalConfigPath(t *testing.T) {
+	base := setupTestHome(t)
+	current := filepath.Join(base, "config", "gym", "config.yaml")
+	legacy := filepath.Join(base, "home", ".gym.yaml")
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   string
+		want  string
+	}{
+		{name: "no config", want: current},
+		{name: "current config", files: map[string]string{current: "skillRepository: /a\n"}, want: current},
+		{name: "legacy config", files: map[string]string{legacy: "skillRepository: /a\n"}, want: legacy},
+		{name: "both", files: map[string]string{current: "skillRepository: /a\n", legacy: "skillRepository: /b\n"}, want: current},
+		{name: "override", files: map[string]string{legacy: "skillRepository: /a\n"}, env: filepath.Join(base, "ci.yaml"), want: filepath.Join(base, "ci.yaml")},
+		{name: "relative override", env: "ci.yaml", want: filepath.Join(base, "ci.yaml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.RemoveAll(filepath.Dir(current))
+			os.Remove(legacy)
+			for path, content := range tt.files {
+				writeTestFiles(t, filepath.Dir(path), map[string]string{filepath.Base(path): content})
+			}
+			t.Setenv(envConfig, tt.env)
+			t.Chdir(base)
+			path, err := globalConfigPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.want {
+				t.Errorf("globalConfigPath = %s, want %s", path, tt.want)
+			}
+			// Resolving the path never moves or creates files.
+			for path, content := range tt.files {
+				if data, err := os.ReadFile(path); err != nil || string(data) != content {
+					t.Errorf("%s changed: %q, %v", path, data, err)
+				}
+			}
+			if _, err := os.Lstat(current); tt.files[current] == "" && !os.IsNotExist(err) {
+				t.Errorf("%s was created", current)
+			}
+		})
+	}
+}
+
+func TestMigrateGlobalMovesLegacyConfig(t *testing.T) {
+	base := setupTestHome(t)
+	current := filepath.Join(base, "config", "gym", "config.yaml")
+	legacy := filepath.Join(base, "home", ".gym.yaml")
+	content := "# mine\nskillRepository: " + filepath.Join(base, "repo") + "\n"
+	writeTestFiles(t, filepath.Dir(legacy), map[string]string{".gym.yaml": content})
+
+	output, err := runCommand(t, migrateCmd(), "--global", "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Would move "+legacy+" to "+current+"\nWould migrate "+legacy+" from version 0 to 1\n" {
+		t.Errorf("dry run printed %q", output)
+	}
+	if _, err := os.Lstat(current); !os.IsNotExist(err) {
+		t.Fatalf("dry run created %s", current)
+	}
+
+	output, err = runCommand(t, migrateCmd(), "--global")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "Moved "+legacy+" to "+current+" (backup in "+filepath.Join(base, "state", "gym", "backups", "gym.yaml.")) ||
+		!strings.Contains(output, "Migrated "+current+" from version 0 to 1\n") {
+		t.Errorf("migrate printed %q", output)
+	}
+	if _, err := os.Lstat(legacy); !os.IsNotExist(err) {
+		t.Errorf("%s was kept: %v", legacy, err)
+	}
+	for _, lock := range []string{legacy + lockSuffix, current + lockSuffix} {
+		if _, err := os.Lstat(lock); !os.IsNotExist(err) {
+			t.Errorf("%s was left behind", lock)
+		}
+	}
+	data, err := os.ReadFile(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# mine\nversion: 1\nskillRepository: " + filepath.Join(base, "repo") + "\n"; string(data) != want {
+		t.Errorf("%s:\n%s\nwant:\n%s", current, data, want)
+	}
+	backups, err := filepath.Glob(filepath.Join(base, "state", "gym", "backups", "gym.yaml.*"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backups = %v, %v", backups, err)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != content {
+		t.Errorf("backup holds %q", data)
+	}
+}
+
+func TestMigrateGlobalKeepsSymlinkedLegacyConfig(t *testing.T) {
+	base := setupTestHome(t)
+	current := filepath.Join(base, "config", "gym", "config.yaml")
+	legacy := filepath.Join(base, "home", ".gym.yaml")
+	dotfile := filepath.Join(base, "dotfiles", "gym.yaml")
+	writeTestFiles(t, filepath.Dir(dotfile), map[string]string{"gym.yaml": "version: 1\nskillRepository: /a\n"})
+	if err := os.Symlink(dotfile, legacy); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	output, err := runCommand(t, migrateCmd(), "--global")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Linked " + current + " to " + dotfile + "; " + legacy + " is a symlink and was left in place\n" +
+		current + " is up to date (version 1)\n"
+	if output != want {
+		t.Errorf("migrate printed %q, want %q", output, want)
+	}
+	for _, link := range []string{legacy, current} {
+		if target, err := os.Readlink(link); err != nil || target != dotfile {
+			t.Errorf("%s -> %s, %v; want a link to %s", link, target, err, dotfile)
+		}
+	}
+
+	// Writes go through the new link to the dotfile.
+	if err := writeGlobalConfig(GlobalConfig{Version: 1, SkillRepository: "/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dotfile); !strings.Contains(string(data), "skillRepository: /b") {
+		t.Errorf("dotfile holds %q", data)
+	}
+}
+
+func TestMigrateGlobalRefusesToReplaceConfig(t *testing.T) {
+	base := setupTestHome(t)
+	current := filepath.Join(base, "config", "gym", "config.yaml")
+	legacy := filepath.Join(base, "home", ".gym.yaml")
+	writeTestFiles(t, filepath.Dir(legacy), map[string]string{".gym.yaml": "skillRepository: /a\n"})
+	writeTestFiles(t, filepath.Dir(current), map[string]string{"config.yaml": "skillRepository: /b\n"})
+
+	if err := moveLegacyGlobalConfig(legacy, current); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("move = %v, want already exists error", err)
+	}
+	if data, _ := os.ReadFile(current); string(data) != "skillRepository: /b\n" {
+		t.Errorf("%s was replaced: %q", current, data)
+	}
+	if _, err := os.Lstat(legacy); err != nil {
+		t.Errorf("%s was removed: %v", legacy, err)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// projectDir holds the --project/-C flag shared by all commands.
+var projectDir string
+
+// startDir returns the directory gym was pointed at: the --project flag if
+// given, otherwise the working directory.
+func startDir() (string, error) {
+	if projectDir != "" {
+		dir, err := filepath.Abs(projectDir)
+		if err != nil {
+			return "", fmt.Errorf("resolve project directory %s: %w", projectDir, err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return "", fmt.Errorf("project directory: %w", err)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("project directory %s is not a directory", dir)
+		}
+		return dir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	return dir, nil
+}
+
+// findProjectRoot walks up from the start directory to the nearest
+// directory containing a project config, the way git looks for .git.
+func findProjectRoot() (string, error) {
+	start, err := startDir()
+	if err != nil {
+		return "", err
+	}
+	dir := start
+	for {
+		exists, err := projectConfigExists(dir)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found in %s or any parent directory; run gym init or pass --project", projectConfigName, start)
+		}
+		dir = parent
+	}
+}
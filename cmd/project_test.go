@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	base := t.TempDir()
+	writeTestFiles(t, base, map[string]string{
+		"outer/.skills.yaml":            "",
+		"outer/inner/.skills.yaml":      "",
+		"outer/inner/src/deep/file.txt": "",
+		"outer/docs/file.txt":           "",
+		"elsewhere/sub/file.txt":        "",
+	})
+	outer, inner := filepath.Join(base, "outer"), filepath.Join(base, "outer", "inner")
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"outer", outer},
+		{"outer/docs", outer},
+		{"outer/inner", inner},
+		{"outer/inner/src/deep", inner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			t.Chdir(filepath.Join(base, filepath.FromSlash(tt.dir)))
+			got, err := findProjectRoot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The working directory may be reported through symlinks.
+			if mustEvalSymlinks(t, got) != mustEvalSymlinks(t, tt.want) {
+				t.Errorf("findProjectRoot() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		t.Chdir(filepath.Join(base, "elsewhere", "sub"))
+		_, err := findProjectRoot()
+		if err == nil || !strings.Contains(err.Error(), "no .skills.yaml found") {
+			t.Errorf("err = %v, want no .skills.yaml found", err)
+		}
+	})
+
+	t.Run("project flag", func(t *testing.T) {
+		t.Chdir(filepath.Join(base, "elsewhere"))
+		projectDir = filepath.Join(inner, "src")
+		t.Cleanup(func() { projectDir = "" })
+		got, err := findProjectRoot()
+		if err != nil || got != inner {
+			t.Errorf("findProjectRoot() = %s, %v; want %s", got, err, inner)
+		}
+		projectDir = filepath.Join(base, "missing")
+		if _, err := findProjectRoot(); err == nil {
+			t.Error("a missing --project directory was accepted")
+		}
+	})
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
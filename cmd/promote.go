@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func promoteCmd() *cobra.Command {
+	var agent string
+	var yes bool
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "promote <skill-name>",
+		Short: "Copy project edits of a skill back into the central repository",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skillName := args[0]
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			projectCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+				return err
+			}
+			if _, ok := projectCfg.SkillMap[skillName]; !ok {
+				return fmt.Errorf("skill %q is not registered in .skills.yaml", skillName)
+			}
+			skillSrc := globalCfg.repository().skill(projectCfg.sourceName(skillName))
+			if _, err := os.Stat(skillSrc.Dir); err != nil {
+				return fmt.Errorf("skill %q not found in repository: %w", projectCfg.sourceName(skillName), err)
+			}
+			if mode, _ := projectCfg.installMode(skillName); mode == installModeLink {
+				fmt.Fprintf(os.Stdout, "Skill %s is linked; project edits already live in the repository\n", skillName)
+				return nil
+			}
+			if skillSrc.Symlinks == symlinkDereference {
+				linked, err := containsSymlinks(skillSrc.Dir)
+				if err != nil {
+					return err
+				}
+				if linked {
+					return fmt.Errorf("skill %q contains symlinks that are dereferenced on install; edit the linked files in the repository instead", skillName)
+				}
+			}
+			rendered, err := skillSrc.rendered()
+			if err != nil {
+				return err
+			}
+			if rendered {
+				return fmt.Errorf("skill %q is rendered from templates or agent sections; edit it in the repository instead", skillName)
+			}
+
+			agent, target, err := selectPromoteTarget(projectRoot, skillName, skillSrc, agent, projectCfg)
+			if err != nil {
+				return err
+			}
+			lock, err := loadProjectLock(projectRoot)
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				// Hard-linked copies match the repository even after an
+				// in-place edit, which has already written through to it.
+				mode, _ := projectCfg.installMode(skillName)
+				if entry := lock.Skills[skillName]; mode == installModeHardlink && entry.Source != "" {
+					current, err := newDirHasher().hashSource(skillSrc)
+					if err != nil {
+						return fmt.Errorf("hash skill %s: %w", skillSrc.Dir, err)
+					}
+					if current != entry.Source {
+						fmt.Fprintf(os.Stdout, "Skill %s is hard-linked; in-place project edits already changed the repository\n", skillName)
+						return nil
+					}
+				}
+				fmt.Fprintf(os.Stdout, "No changes to promote for %s\n", skillName)
+				return nil
+			}
+			if !force {
+				if err := ensureRepositoryUnchanged(lock, skillName, skillSrc); err != nil {
+					return err
+				}
+			}
+
+			// Files the agent never received are left out of the diff and
+			// are kept in the repository.
+			agentSrc := skillSrc.forAgent(agent, projectCfg.Filters[agent])
+			changed, err := writeDirDiff(os.Stdout, agentSrc, plainSource(target))
+			if err != nil {
+				return fmt.Errorf("diff %s against %s: %w", target, skillSrc.Dir, err)
+			}
+			if !changed {
+				fmt.Fprintf(os.Stdout, "No changes to promote for %s\n", skillName)
+				return nil
+			}
+			if !yes {
+				ok, err := promptConfirm(stdinReader, os.Stdout, fmt.Sprintf("Promote %s from %s into %s?", skillName, agent, skillSrc.Dir))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Fprintln(os.Stdout, "Promotion cancelled")
+					return nil
+				}
+			}
+
+			if err := promoteSkillDir(target, agentSrc); err != nil {
+				return fmt.Errorf("copy skill to %s: %w", skillSrc.Dir, err)
+			}
+			if err := recordInstall(&lock, skillName, skillSrc, map[string]string{agent: target}); err != nil {
+				return err
+			}
+			if err := writeProjectLock(projectRoot, lock); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Promoted %s for %s -> %s\n", skillName, agent, skillSrc.Dir)
+			if len(projectCfg.skillAgents(skillName)) > 1 {
+				fmt.Fprintln(os.Stdout, "Run gym sync to update the other agents")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&agent, "agent", "", "agent whose copy of the skill is promoted")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "promote without asking for confirmation")
+	cmd.Flags().BoolVar(&force, "force", false, "promote even if the repository skill changed since install")
+	return cmd
+}
+
+// selectPromoteTarget picks the agent copy to promote. Without an explicit
+// agent it uses the only copy that differs from the repository; an empty
+// target means every copy is in sync.
+func selectPromoteTarget(projectRoot, skillName string, skillSrc skillSource, agent string, projectCfg ProjectConfig) (string, string, error) {
+	agents := projectCfg.skillAgents(skillName)
+	overrides := projectCfg.SkillMap[skillName].Paths
+	if agent != "" {
+		if !slices.Contains(agents, agent) {
+			return "", "", fmt.Errorf("skill %q is not installed for agent %q in .skills.yaml", skillName, agent)
+		}
+		target, err := resolveSkillTarget(projectRoot, skillName, agent, overrides)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(target); err != nil {
+			return "", "", fmt.Errorf("skill %q is not installed for %s: %w", skillName, agent, err)
+		}
+		return agent, target, nil
+	}
+
+	changed := make([]string, 0, len(agents))
+	targets := map[string]string{}
+	for _, candidate := range agents {
+		target, err := resolveSkillTarget(projectRoot, skillName, candidate, overrides)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(target); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", "", err
+		}
+		match, err := dirsEqual(skillSrc.forAgent(candidate, projectCfg.Filters[candidate]), target)
+		if err != nil {
+			return "", "", err
+		}
+		if !match {
+			changed = append(changed, candidate)
+			targets[candidate] = target
+		}
+	}
+	switch len(changed) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return changed[0], targets[changed[0]], nil
+	}
+	return "", "", fmt.Errorf("skill %q was changed for several agents (%s); choose one with --agent", skillName, strings.Join(changed, ", "))
+}
+
+func ensureRepositoryUnchanged(lock ProjectLock, skillName string, skillSrc skillSource) error {
+	entry, ok := lock.Skills[skillName]
+	if !ok || entry.Source == "" {
+		return fmt.Errorf("no install record for %q in %s; use --force to promote anyway", skillName, projectLockName)
+	}
+	current, err := newDirHasher().hashSource(skillSrc)
+	if err != nil {
+		return fmt.Errorf("hash skill %s: %w", skillSrc.Dir, err)
+	}
+	if current != entry.Source {
+		return fmt.Errorf("skill %q changed in the repository since it was installed; refusing to overwrite (use --force to promote anyway)", skillName)
+	}
+	return nil
+}
+
+// promoteSkillDir copies an installed target over its repository skill.
+// Only entries that src installs are deleted when missing from target, so
+// ignored and filtered-out files stay in the repository.
+func promoteSkillDir(target string, src skillSource) error {
+	installed, err := src.entryNames()
+	if err != nil {
+		return err
+	}
+	promoted := map[string]bool{}
+	if err := plainSource(target).walk(func(entry skillEntry) error {
+		promoted[entry.Rel] = true
+		dst := filepath.Join(src.Dir, entry.Rel)
+		// A hard-linked target already is the repository file.
+		if info, err := os.Lstat(dst); err == nil && entry.Info.Mode().IsRegular() && os.SameFile(entry.Info, info) {
+			return nil
+		}
+		return syncEntry(entry, dst, copyPlacement)
+	}); err != nil {
+		return err
+	}
+
+	removed := make([]string, 0)
+	for rel := range installed {
+		if !promoted[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	// Children sort after their parents, so walking backwards empties a
+	// directory before it is removed. Directories still holding ignored
+	// files are kept.
+	sort.Sort(sort.Reverse(sort.StringSlice(removed)))
+	for _, rel := range removed {
+		path := filepath.Join(src.Dir, rel)
+		info, err := os.Lstat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			if len(entries) > 0 {
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsSymlinks reports whether any entry below dir is a symlink.
+func containsSymlinks(dir string) (bool, error) {
+	found := false
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func renameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old-name> <new-name>",
+		Short: "Change the local name of an installed skill and move its targets",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldName, newName := args[0], args[1]
+			if err := validateSkillName(newName); err != nil {
+				return err
+			}
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
+			projectCfg, err := loadProjectConfig(projectRoot)
+			if err != nil {
+				return err
+			}
+			ownCfg, err := readProjectConfigFile(projectRoot)
+			if err != nil {
+				return err
+			}
+			skillCfg, owned := ownCfg.SkillMap[oldName]
+			if !owned {
+				if _, inherited := projectCfg.SkillMap[oldName]; inherited {
+					return fmt.Errorf("skill %q is inherited from an enclosing %s; rename it there", oldName, projectConfigName)
+				}
+				return fmt.Errorf("skill %q is not registered in .skills.yaml", oldName)
+			}
+			if _, exists := projectCfg.SkillMap[newName]; exists {
+				return fmt.Errorf("skill %q is already registered", newName)
+			}
+			renamedCfg := projectCfg
+			renamedCfg.SkillMap = maps.Clone(projectCfg.SkillMap)
+			delete(renamedCfg.SkillMap, oldName)
+			renamedCfg.SkillMap[newName] = skillCfg
+			if err := validateSkillTargets(renamedCfg); err != nil {
+				return err
+			}
+
+			// All new targets are checked before anything moves, so a clash
+			// does not leave the skill half renamed.
+			moves := make([][2]string, 0)
+			for _, agent := range projectCfg.skillAgents(oldName) {
+				oldTarget, err := resolveSkillTarget(projectRoot, oldName, agent, skillCfg.Paths)
+				if err != nil {
+					return err
+				}
+				newTarget, err := resolveSkillTarget(projectRoot, newName, agent, skillCfg.Paths)
+				if err != nil {
+					return err
+				}
+				if oldTarget == newTarget {
+					continue
+				}
+				if _, err := os.Lstat(newTarget); err == nil {
+					return fmt.Errorf("target %s already exists", newTarget)
+				}
+				moves = append(moves, [2]string{oldTarget, newTarget})
+			}
+			for _, move := range moves {
+				if _, err := os.Lstat(move[0]); os.IsNotExist(err) {
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(move[1]), 0o755); err != nil {
+					return fmt.Errorf("create %s: %w", filepath.Dir(move[1]), err)
+				}
+				if err := os.Rename(move[0], move[1]); err != nil {
+					return fmt.Errorf("move skill to %s: %w", move[1], err)
+				}
+				fmt.Fprintf(os.Stdout, "Moved %s -> %s\n", move[0], move[1])
+			}
+
+			if skillCfg.Source == "" {
+				skillCfg.Source = oldName
+			}
+			if skillCfg.Source == newName {
+				skillCfg.Source = ""
+			}
+			delete(ownCfg.SkillMap, oldName)
+			ownCfg.SkillMap[newName] = skillCfg
+			if err := writeProjectConfig(projectRoot, ownCfg); err != nil {
+				return err
+			}
+			lock, err := loadProjectLock(projectRoot)
+			if err != nil {
+				return err
+			}
+			if entry, ok := lock.Skills[oldName]; ok {
+				delete(lock.Skills, oldName)
+				lock.Skills[newName] = entry
+			}
+			if err := writeProjectLock(projectRoot, lock); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Renamed %s to %s\n", oldName, newName)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupRenameProject installs alpha for codex and, through a path
+// override, for pi.
+func setupRenameProject(t *testing.T) (string, skillRepository) {
+	t.Helper()
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{"beta/SKILL.md": "beta\n"})
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex, pi]\nskillMap:\n  alpha:\n    pi: tools/alpha\n  beta: {}\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+	if _, err := runCommand(t, syncCmd()); err != nil {
+		t.Fatal(err)
+	}
+	return project, repo
+}
+
+func TestRenameMovesTargetsAndLock(t *testing.T) {
+	project, _ := setupRenameProject(t)
+	before, err := loadProjectLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, renameCmd(), "alpha", "gamma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Moved " + filepath.Join(project, ".codex", "skills", "alpha") + " -> " + filepath.Join(project, ".codex", "skills", "gamma") + "\n" +
+		"Renamed alpha to gamma\n"
+	if output != want {
+		t.Errorf("rename printed:\n%s\nwant:\n%s", output, want)
+	}
+	for rel, exists := range map[string]bool{
+		".codex/skills/alpha": false,
+		".codex/skills/gamma": true,
+		"tools/alpha":         true,
+		".pi/skills/gamma":    false,
+	} {
+		if _, err := os.Lstat(filepath.Join(project, rel)); (err == nil) != exists {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, exists)
+		}
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.SkillMap["alpha"]; ok {
+		t.Error("alpha is still registered")
+	}
+	if gamma := cfg.SkillMap["gamma"]; gamma.Source != "alpha" || gamma.Paths["pi"] != "tools/alpha" {
+		t.Errorf("gamma = %+v, want source alpha with the pi override", gamma)
+	}
+	lock, err := loadProjectLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lock.Skills["alpha"]; ok {
+		t.Error("lock still has alpha")
+	}
+	if lock.Skills["gamma"].Source != before.Skills["alpha"].Source || len(lock.Skills["gamma"].Targets) != 2 {
+		t.Errorf("lock entry gamma = %+v, want %+v", lock.Skills["gamma"], before.Skills["alpha"])
+	}
+
+	// The renamed skill is in sync, and renaming back drops the source.
+	if output, err := runCommand(t, driftCmd()); err != nil || output != "No drifting skills found\n" {
+		t.Errorf("drift after rename = %v:\n%s", err, output)
+	}
+	if _, err := runCommand(t, renameCmd(), "gamma", "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alpha := cfg.SkillMap["alpha"]; alpha.Source != "" || alpha.Paths["pi"] != "tools/alpha" {
+		t.Errorf("alpha = %+v", alpha)
+	}
+}
+
+func TestRenameRefusesWithoutTouchingAnything(t *testing.T) {
+	project, _ := setupRenameProject(t)
+	writeTestFiles(t, project, map[string]string{"tools/delta/keep.md": "mine\n"})
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		err    string
+	}{
+		{name: "registered name", args: []string{"alpha", "beta"}, err: `skill "beta" is already registered`},
+		{name: "unknown skill", args: []string{"omega", "delta"}, err: `skill "omega" is not registered in .skills.yaml`},
+		{name: "invalid name", args: []string{"alpha", "../delta"}, err: `invalid skill name "../delta"`},
+		{
+			name: "occupied target",
+			// Only the pi target clashes, so the codex move must not happen
+			// either.
+			config: "agents: [codex, pi]\nskillMap:\n  alpha: {}\n  beta:\n    pi: tools/beta\n",
+			args:   []string{"alpha", "delta"},
+			err:    "already exists",
+		},
+		{
+			name:   "overlapping override",
+			config: "agents: [codex, pi]\nskillMap:\n  alpha:\n    pi: tools/alpha\n  beta:\n    codex: .codex/skills/delta\n",
+			args:   []string{"alpha", "delta"},
+			err:    "overlaps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.config != "" {
+				writeTestFiles(t, project, map[string]string{projectConfigName: tt.config})
+			}
+			if tt.name == "occupied target" {
+				writeTestFiles(t, project, map[string]string{".pi/skills/delta/keep.md": "mine\n"})
+				t.Cleanup(func() { os.RemoveAll(filepath.Join(project, ".pi", "skills", "delta")) })
+			}
+			config, _ := os.ReadFile(filepath.Join(project, projectConfigName))
+			lock, _ := os.ReadFile(filepath.Join(project, projectLockName))
+			_, err := runCommand(t, renameCmd(), tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("rename = %v, want error containing %q", err, tt.err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(project, projectConfigName)); string(data) != string(config) {
+				t.Errorf("config changed:\n%s", data)
+			}
+			if data, _ := os.ReadFile(filepath.Join(project, projectLockName)); string(data) != string(lock) {
+				t.Errorf("lock changed:\n%s", data)
+			}
+			if _, err := os.Stat(filepath.Join(project, ".codex", "skills", "alpha", "SKILL.md")); err != nil {
+				t.Errorf("alpha was moved: %v", err)
+			}
+			if _, err := os.Lstat(filepath.Join(project, ".codex", "skills", "delta")); !os.IsNotExist(err) {
+				t.Errorf("delta was created: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddAs(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{"beta/SKILL.md": "beta\n"})
+	project := filepath.Join(base, "project")
+	setupTestProject(t, project)
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	if _, err := runCommand(t, addCmd(), "alpha", "--as", "local"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(project, ".codex", "skills", "local", "SKILL.md")); err != nil || string(data) != "alpha\n" {
+		t.Errorf("local/SKILL.md = %q, %v", data, err)
+	}
+	if _, err := os.Lstat(filepath.Join(project, ".codex", "skills", "alpha")); !os.IsNotExist(err) {
+		t.Errorf("alpha was installed under its own name: %v", err)
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local, ok := cfg.SkillMap["local"]; !ok || local.Source != "alpha" || len(cfg.SkillMap) != 1 {
+		t.Errorf("skillMap = %+v", cfg.SkillMap)
+	}
+	lock, err := loadProjectLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceHash, err := newDirHasher().hashSource(repo.skill("alpha"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := lock.Skills["local"]; !ok || entry.Source != sourceHash {
+		t.Errorf("lock entry local = %+v, want source hash %s", entry, sourceHash)
+	}
+
+	_, err = runCommand(t, addCmd(), "beta", "--as", "local")
+	if err == nil || err.Error() != "skill local is already installed from alpha; choose another name with --as" {
+		t.Errorf("add beta --as local = %v", err)
+	}
+	if _, err := runCommand(t, addCmd(), "alpha", "--as", ".hidden"); err == nil || !strings.HasPrefix(err.Error(), "--as: ") {
+		t.Errorf("add --as .hidden = %v", err)
+	}
+	// Adding the source under its own name installs a second copy.
+	if _, err := runCommand(t, addCmd(), "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".codex", "skills", "alpha", "SKILL.md")); err != nil {
+		t.Errorf("alpha not installed: %v", err)
+	}
+}
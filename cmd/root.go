@@ -7,16 +7,35 @@ var rootCmd = &cobra.Command{
 	Short: "gym manages synchronization of agent skills into projects",
 }
 
-// Execute runs the root command.
-func Execute() error {
+// Execute runs the root command. The hash cache is saved even when the
+// command fails, so a drifting project in CI still caches its hashes.
+func Execute() (err error) {
+	defer func() {
+		if saveErr := saveHashCache(); err == nil {
+			err = saveErr
+		}
+	}()
 	return rootCmd.Execute()
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&projectDir, "project", "C", "", "run as if gym was started in this directory")
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(listCmd())
 	rootCmd.AddCommand(addCmd())
 	rootCmd.AddCommand(removeCmd())
+	rootCmd.AddCommand(renameCmd())
 	rootCmd.AddCommand(syncCmd())
 	rootCmd.AddCommand(driftCmd())
+	rootCmd.AddCommand(promoteCmd())
+	rootCmd.AddCommand(cacheCmd())
+	rootCmd.AddCommand(watchCmd())
+	rootCmd.AddCommand(workspaceCmd())
+	rootCmd.AddCommand(usagesCmd())
+	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(lintCmd())
+	rootCmd.AddCommand(schemaCmd())
+	rootCmd.AddCommand(migrateCmd())
+	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(agentCmd())
 }
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// validateSkillName rejects names that are not a single plain directory
+// name, since skill names are joined onto the repository path and onto
+// agent directories in the project.
+func validateSkillName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("skill name is empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid skill name %q", name)
+	case strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, 0):
+		return fmt.Errorf("invalid skill name %q: must not contain path separators", name)
+	case strings.HasPrefix(name, "."):
+		return fmt.Errorf("invalid skill name %q: must not start with a dot", name)
+	case filepath.IsAbs(name) || filepath.VolumeName(name) != "":
+		return fmt.Errorf("invalid skill name %q: must not be an absolute path", name)
+	}
+	return nil
+}
+
+// validateTargetOverride checks a skillMap path override. Overrides must
+// stay inside the project and must not point at the project root, at an
+// agent's skill directory (or one of its parents), into .git or at gym's
+// own config and lock files, because installing a skill deletes whatever
+// is at its target.
+func validateTargetOverride(agent, override string) error {
+	if filepath.IsAbs(override) || filepath.VolumeName(override) != "" || hasDriveLetter(override) || strings.HasPrefix(override, "/") || strings.HasPrefix(override, `\`) {
+		return fmt.Errorf("target for %s %q must be relative to the project root", agent, override)
+	}
+	clean := filepath.Clean(filepath.FromSlash(override))
+	if clean == "." {
+		return fmt.Errorf("target for %s %q must not be the project root", agent, override)
+	}
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("target for %s %q escapes the project root", agent, override)
+	}
+	for _, part := range strings.Split(clean, string(filepath.Separator)) {
+		switch strings.ToLower(part) {
+		case ".git":
+			return fmt.Errorf("target for %s %q must not be inside .git", agent, override)
+		case projectConfigName, projectLockName:
+			return fmt.Errorf("target for %s %q must not replace a %s file", agent, override, part)
+		}
+	}
+	for _, baseDir := range supportedAgents {
+		base := filepath.Clean(filepath.FromSlash(baseDir))
+		if clean == base || strings.HasPrefix(base, clean+string(filepath.Separator)) {
+			return fmt.Errorf("target for %s %q must not be the agent skill directory %s or one of its parents", agent, override, baseDir)
+		}
+	}
+	return nil
+}
+
+// validateSkillTargets rejects configs where the target of one skill is
+// the target of another skill, or lies inside it, since installing either
+// would delete the other.
+func validateSkillTargets(cfg ProjectConfig) error {
+	type skillTarget struct{ skill, agent, path string }
+	var targets []skillTarget
+	names := make([]string, 0, len(cfg.SkillMap))
+	for name := range cfg.SkillMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, agent := range cfg.skillAgents(name) {
+			path := cfg.SkillMap[name].Paths[agent]
+			if path == "" {
+				baseDir, err := defaultSkillDir(agent)
+				if err != nil {
+					return err
+				}
+				path = filepath.Join(baseDir, name)
+			}
+			targets = append(targets, skillTarget{name, agent, filepath.Clean(filepath.FromSlash(path))})
+		}
+	}
+	for i, a := range targets {
+		for _, b := range targets[i+1:] {
+			if a.skill == b.skill && a.path == b.path {
+				continue
+			}
+			if a.path == b.path || strings.HasPrefix(a.path, b.path+string(filepath.Separator)) || strings.HasPrefix(b.path, a.path+string(filepath.Separator)) {
+				return fmt.Errorf("target %s of skill %q for %s overlaps target %s of skill %q for %s", filepath.ToSlash(a.path), a.skill, a.agent, filepath.ToSlash(b.path), b.skill, b.agent)
+			}
+		}
+	}
+	return nil
+}
+
+// hasDriveLetter reports whether path starts with a Windows drive such as
+// C:, which filepath only recognizes on Windows. Configs are shared across
+// platforms, so such paths are rejected everywhere.
+func hasDriveLetter(path string) bool {
+	return len(path) >= 2 && path[1] == ':' && ('a' <= path[0] && path[0] <= 'z' || 'A' <= path[0] && path[0] <= 'Z')
+}
+
+// validateSkillConfig checks a skillMap entry of a project config.
+func validateSkillConfig(skillName string, skill SkillConfig) error {
+	if err := validateSkillName(skillName); err != nil {
+		return err
+	}
+	for agent, override := range skill.Paths {
+		if override == "" {
+			continue
+		}
+		if err := validateTargetOverride(agent, override); err != nil {
+			return fmt.Errorf("skill %q: %w", skillName, err)
+		}
+	}
+	if skill.Source != "" {
+		if err := validateSkillName(skill.Source); err != nil {
+			return fmt.Errorf("skill %q: source: %w", skillName, err)
+		}
+	}
+	if err := ensureSupportedAgents(skill.Agents); err != nil {
+		return fmt.Errorf("skill %q: agents: %w", skillName, err)
+	}
+	if err := validateVarNames(skill.Vars); err != nil {
+		return fmt.Errorf("skill %q: %w", skillName, err)
+	}
+	return nil
+}
+
+// validateVarNames checks that template variables can be referenced as
+// {{ .name }}, which needs a Go identifier.
+func validateVarNames(vars map[string]string) error {
+	for name := range vars {
+		if !isIdentifier(name) {
+			return fmt.Errorf("invalid variable name %q: must be a letter or underscore followed by letters, digits or underscores", name)
+		}
+	}
+	return nil
+}
+
+func validateAgentFilter(filter AgentFilter) error {
+	if _, err := compileGlobs(filter.Include); err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+	if _, err := compileGlobs(filter.Exclude); err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+	return nil
+}
+
+// ensureWithin returns an error unless path is strictly inside root, both
+// lexically and after resolving symlinks in the directories leading to it.
+func ensureWithin(root, path string) error {
+	if !isWithin(root, path, false) {
+		return fmt.Errorf("path %s is outside %s", path, root)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", root, err)
+	}
+	parent := filepath.Dir(path)
+	for {
+		realParent, err := filepath.EvalSymlinks(parent)
+		if err == nil {
+			if !isWithin(realRoot, realParent, true) {
+				return fmt.Errorf("path %s resolves outside %s", path, root)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("resolve %s: %w", parent, err)
+		}
+		parent = filepath.Dir(parent)
+	}
+}
+
+func isWithin(root, path string, allowEqual bool) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	if rel == "." {
+		return allowEqual
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
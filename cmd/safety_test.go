@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSkillName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"testing", true},
+		{"go-testing.v2", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../../etc", false},
+		{"/abs", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{".hidden", false},
+		{"nul\x00byte", false},
+	}
+	for _, tt := range tests {
+		err := validateSkillName(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateSkillName(%q) = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateTargetOverride(t *testing.T) {
+	tests := []struct {
+		override string
+		want     string
+	}{
+		{"tools/skills/testing", ""},
+		{".codex/skills/testing", ""},
+		{"../", "escapes the project root"},
+		{"../outside", "escapes the project root"},
+		{"a/../../outside", "escapes the project root"},
+		{".", "must not be the project root"},
+		{"a/..", "must not be the project root"},
+		{".codex", "agent skill directory"},
+		{".codex/skills", "agent skill directory"},
+		{".codex/skills/", "agent skill directory"},
+		{"/etc", "must be relative"},
+		{`\etc`, "must be relative"},
+		{`C:\x`, "must be relative"},
+		{"c:x", "must be relative"},
+		{".git", "inside .git"},
+		{".git/hooks/pre-commit", "inside .git"},
+		{"vendor/lib/.GIT/x", "inside .git"},
+		{".gitignore-skills/testing", ""},
+		{".skills.yaml", "must not replace a .skills.yaml file"},
+		{".skills.lock", "must not replace a .skills.lock file"},
+		{"app/.skills.yaml", "must not replace a .skills.yaml file"},
+	}
+	for _, tt := range tests {
+		err := validateTargetOverride("codex", tt.override)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateTargetOverride(%q) = %v, want ok", tt.override, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("validateTargetOverride(%q) = %v, want error containing %q", tt.override, err, tt.want)
+		}
+	}
+}
+
+func TestValidateSkillTargets(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "distinct defaults",
+			config: "agents: [codex, pi]\nskillMap:\n  alpha: {}\n  beta: {}\n",
+		},
+		{
+			name:   "same override for several agents of one skill",
+			config: "agents: [codex, pi]\nskillMap:\n  alpha:\n    codex: tools/alpha\n    pi: tools/alpha\n",
+		},
+		{
+			name:   "override onto another skill's default target",
+			config: "agents: [codex]\nskillMap:\n  alpha:\n    codex: .codex/skills/beta\n  beta: {}\n",
+			want:   `overlaps target .codex/skills/beta of skill "beta"`,
+		},
+		{
+			name:   "override inside another skill's target",
+			config: "agents: [codex]\nskillMap:\n  alpha: {}\n  beta:\n    codex: .codex/skills/alpha/nested\n",
+			want:   `target .codex/skills/alpha of skill "alpha" for codex overlaps target .codex/skills/alpha/nested of skill "beta"`,
+		},
+		{
+			name:   "same override for two skills",
+			config: "agents: [codex, pi]\nskillMap:\n  alpha:\n    pi: shared\n  beta:\n    codex: shared\n",
+			want:   "overlaps target shared",
+		},
+		{
+			name:   "overlap only for an agent the skill does not use",
+			config: "agents: [codex, pi]\nskillMap:\n  alpha:\n    agents: [pi]\n    codex: .codex/skills/beta\n  beta: {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTestFiles(t, root, map[string]string{projectConfigName: tt.config})
+			_, err := loadProjectConfig(root)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("loadProjectConfig = %v, want ok", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("loadProjectConfig = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureWithin(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "project")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, ".codex", "skills"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, ".codex"), filepath.Join(root, "inside")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{filepath.Join(root, ".codex", "skills", "testing"), true},
+		{filepath.Join(root, "not", "yet", "created"), true},
+		{filepath.Join(root, "inside", "skills", "testing"), true},
+		{root, false},
+		{filepath.Join(root, ".."), false},
+		{filepath.Join(outside, "testing"), false},
+		{filepath.Join(root, "escape", "testing"), false},
+		{filepath.Join(root, "escape", "missing", "testing"), false},
+	}
+	for _, tt := range tests {
+		err := ensureWithin(root, tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("ensureWithin(%s) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
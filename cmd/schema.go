@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// extensionKeyPrefix marks config keys that gym ignores, so files can hold
+// anchors and other tool settings without failing strict decoding.
+const extensionKeyPrefix = "x-"
+
+// schemaEnums lists the allowed values of string settings by YAML key.
+var schemaEnums = map[string][]string{
+	"mode":      {installModeCopy, installModeLink, installModeHardlink, installModeReflink},
+	"linkStyle": {linkStyleRelative, linkStyleAbsolute},
+	"symlinks":  {symlinkPreserve, symlinkDereference, symlinkReject},
+}
+
+// decodeConfig parses a config file strictly, rejecting unknown keys with
+// their line number. Files written for an older schema are migrated in
+// memory; gym migrate rewrites them on disk.
+func decodeConfig(data []byte, out interface{}, version int, migrations []configMigration) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		return nil
+	}
+	if _, err := migrateConfigNode(&doc, version, migrations); err != nil {
+		return err
+	}
+	if err := checkKnownKeys(&doc, reflect.TypeOf(out)); err != nil {
+		return err
+	}
+	return doc.Decode(out)
+}
+
+// checkKnownKeys reports the first mapping key that has no field in typ, so
+// typos in config files fail loudly instead of being ignored. Types with
+// their own UnmarshalYAML check their keys themselves.
+func checkKnownKeys(node *yaml.Node, typ reflect.Type) error {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return checkKnownKeys(node.Content[0], typ)
+	case yaml.AliasNode:
+		return checkKnownKeys(node.Alias, typ)
+	}
+	if reflect.PointerTo(typ).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := yamlFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				if err := checkKnownKeys(value, typ); err != nil {
+					return err
+				}
+				continue
+			}
+			if strings.HasPrefix(key.Value, extensionKeyPrefix) {
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				return fmt.Errorf("line %d: unknown key %q", key.Line, key.Value)
+			}
+			if err := checkKnownKeys(value, field.Type); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := checkKnownKeys(node.Content[i], typ.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range node.Content {
+			if err := checkKnownKeys(item, typ.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func schemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "schema <project|global>",
+		Short:     "Print the JSON Schema of a config file for editor completion",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"project", "global"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var schema map[string]interface{}
+			switch args[0] {
+			case "project":
+				schema = configSchema(reflect.TypeOf(ProjectConfig{}), "gym project config ("+projectConfigName+")", projectConfigVersion)
+			case "global":
+				schema = configSchema(reflect.TypeOf(GlobalConfig{}), "gym global config", globalConfigVersion)
+			default:
+				return fmt.Errorf("unknown schema %q (want project or global)", args[0])
+			}
+			data, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal schema: %w", err)
+			}
+			fmt.Fprintln(os.Stdout, string(data))
+			return nil
+		},
+	}
+}
+
+func configSchema(typ reflect.Type, title string, version int) map[string]interface{} {
+	schema := typeSchema(typ, "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = title
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		properties["version"] = map[string]interface{}{"type": "integer", "minimum": 0, "maximum": version}
+	}
+	return schema
+}
+
+// typeSchema derives a JSON Schema from the Go type a config is decoded
+// into. key is the YAML key the value appears under, used for enums.
+func typeSchema(typ reflect.Type, key string) map[string]interface{} {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(SkillConfig{}) {
+		return skillConfigSchema()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for name, field := range yamlFields(typ) {
+			properties[name] = typeSchema(field.Type, name)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"patternProperties":    map[string]interface{}{"^" + extensionKeyPrefix: map[string]interface{}{}},
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(typ.Elem(), "")}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(typ.Elem(), "")}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	}
+	schema := map[string]interface{}{"type": "string"}
+	if values, ok := schemaEnums[key]; ok {
+		schema["enum"] = values
+	}
+	return schema
+}
+
+// skillConfigSchema describes a skillMap entry, whose agent path overrides
+// sit inline next to the skill settings.
+func skillConfigSchema() map[string]interface{} {
+	properties := map[string]interface{}{
+		"mode":      typeSchema(reflect.TypeOf(""), "mode"),
+		"linkStyle": typeSchema(reflect.TypeOf(""), "linkStyle"),
+		"source": map[string]interface{}{
+			"type":        "string",
+			"description": "repository skill installed under this name; defaults to the name itself",
+		},
+		"agents": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string", "enum": listSupportedAgents()},
+			"description": "agents the skill is installed for; defaults to every project agent",
+		},
+		"vars": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"description":          "template variables for this skill, overriding the project-wide vars",
+		},
+	}
+	for agent := range supportedAgents {
+		properties[agent] = map[string]interface{}{"type": "string", "description": "custom target path for " + agent}
+	}
+	return map[string]interface{}{
+		"type":                 []string{"object", "null"},
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
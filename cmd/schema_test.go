@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeConfigRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "valid",
+			config: "version: 1\nagents: [codex]\nfilters:\n  codex:\n    include: [docs/]\nskillMap:\n  alpha:\n    codex: tools/alpha\n    mode: link\n",
+		},
+		{
+			name:   "top level typo",
+			config: "agents: [codex]\nmodee: link\nskillMap: {}\n",
+			want:   `line 2: unknown key "modee"`,
+		},
+		{
+			name:   "nested typo",
+			config: "agents: [codex]\nfilters:\n  codex:\n    includes: [docs/]\nskillMap: {}\n",
+			want:   `line 4: unknown key "includes"`,
+		},
+		{
+			name:   "skill typo",
+			config: "agents: [codex]\nskillMap:\n  alpha:\n    sourc: beta\n",
+			want:   `line 4: unknown key "sourc" (not a skill setting or supported agent)`,
+		},
+		{
+			name:   "extension keys",
+			config: "x-linked: &linked\n  mode: link\nagents: [codex]\nskillMap:\n  alpha: *linked\n  beta: *linked\n",
+		},
+		{
+			name:   "merge key checked against the target type",
+			config: "x-defaults: &defaults\n  modee: link\nagents: [codex]\nfilters:\n  codex:\n    <<: *defaults\nskillMap: {}\n",
+			want:   `line 2: unknown key "modee"`,
+		},
+		{
+			name:   "newer version",
+			config: "version: 2\nagents: [codex]\nskillMap: {}\n",
+			want:   "schema version 2 is newer than this gym supports (1)",
+		},
+		{
+			name:   "bad version",
+			config: "version: one\nagents: [codex]\nskillMap: {}\n",
+			want:   "line 1: version must be a non-negative integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg ProjectConfig
+			err := decodeConfig([]byte(tt.config), &cfg, projectConfigVersion, projectMigrations)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("decodeConfig = %v, want ok", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("decodeConfig = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeConfigKeepsFileVersion(t *testing.T) {
+	for config, want := range map[string]int{
+		"agents: [codex]\nskillMap: {}\n":             0,
+		"version: 0\nagents: [codex]\nskillMap: {}\n": 0,
+		"version: 1\nagents: [codex]\nskillMap: {}\n": 1,
+	} {
+		var cfg ProjectConfig
+		if err := decodeConfig([]byte(config), &cfg, projectConfigVersion, projectMigrations); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Version != want {
+			t.Errorf("version of %q = %d, want %d", config, cfg.Version, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Agent sections keep instructions for some agents only in a markdown
+// file of a skill. Each marker sits on a line of its own:
+//
+//	<!-- gym:if agent=codex,pi -->
+//	...
+//	<!-- gym:else -->
+//	...
+//	<!-- gym:end -->
+//
+// agent!=name selects every agent but the listed ones. Sections nest, and
+// marker lines are dropped from the installed file. Markers inside fenced
+// code blocks are content, so skills can document the syntax.
+const agentMarkerPrefix = "<!-- gym:"
+
+var agentMarkerPattern = regexp.MustCompile(`^\s*<!--\s*gym:(\S+)\s*(.*?)\s*-->\s*$`)
+
+func isMarkdownFile(path string) bool {
+	ext := filepath.Ext(path)
+	return strings.EqualFold(ext, ".md") || strings.EqualFold(ext, ".markdown")
+}
+
+func containsAgentSections(data []byte) bool {
+	if !bytes.Contains(data, []byte(agentMarkerPrefix)) {
+		return false
+	}
+	var fence codeFence
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if !fence.scan(line) && bytes.Contains(line, []byte(agentMarkerPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeFence follows the fenced code blocks (``` or ~~~) of a markdown file
+// line by line.
+type codeFence struct {
+	char   byte
+	length int
+}
+
+// scan reports whether line opens, closes or lies inside a fenced block.
+func (f *codeFence) scan(line []byte) bool {
+	line = bytes.TrimRight(line, "\r\n")
+	rest := bytes.TrimLeft(line, " ")
+	indent := len(line) - len(rest)
+	n := 0
+	if indent <= 3 && len(rest) > 0 && (rest[0] == '`' || rest[0] == '~') {
+		for n < len(rest) && rest[n] == rest[0] {
+			n++
+		}
+	}
+	if f.length > 0 {
+		if n >= f.length && rest[0] == f.char && len(bytes.TrimSpace(rest[n:])) == 0 {
+			f.length = 0
+		}
+		return true
+	}
+	// A backtick fence's info string must not contain backticks, which
+	// tells it apart from inline code at the start of a line.
+	if n >= 3 && (rest[0] == '~' || bytes.IndexByte(rest[n:], '`') < 0) {
+		f.char, f.length = rest[0], n
+		return true
+	}
+	return false
+}
+
+// agentCondition is the condition of a gym:if marker.
+type agentCondition struct {
+	agents []string
+	negate bool
+}
+
+func parseAgentCondition(text string) (agentCondition, error) {
+	var cond agentCondition
+	value, ok := strings.CutPrefix(text, "agent!=")
+	if ok {
+		cond.negate = true
+	} else if value, ok = strings.CutPrefix(text, "agent="); !ok {
+		return cond, fmt.Errorf("invalid condition %q (want agent=<names> or agent!=<names>)", text)
+	}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return cond, fmt.Errorf("invalid condition %q: empty agent name", text)
+		}
+		cond.agents = append(cond.agents, name)
+	}
+	return cond, nil
+}
+
+func (c agentCondition) matches(agent string) bool {
+	return slices.Contains(c.agents, agent) != c.negate
+}
+
+// agentSection is an open gym:if section while resolving a file.
+type agentSection struct {
+	line      int
+	outer     bool
+	matched   bool
+	afterElse bool
+}
+
+// resolveAgentSections keeps the parts of data meant for agent and drops
+// the marker lines. It also returns every agent name the conditions
+// mention. With an empty agent no condition matches, which still checks
+// the structure of the file.
+func resolveAgentSections(data []byte, agent string) ([]byte, []string, error) {
+	out := make([]byte, 0, len(data))
+	var names []string
+	var open []agentSection
+	emitting := true
+	var fence codeFence
+	for i, line := range bytes.SplitAfter(data, []byte("\n")) {
+		lineNo := i + 1
+		var match [][]byte
+		if !fence.scan(line) {
+			match = agentMarkerPattern.FindSubmatch(bytes.TrimRight(line, "\r\n"))
+		}
+		if match == nil {
+			if emitting {
+				out = append(out, line...)
+			}
+			continue
+		}
+		directive, arg := string(match[1]), string(match[2])
+		switch directive {
+		case "if":
+			cond, err := parseAgentCondition(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			for _, name := range cond.agents {
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+			section := agentSection{line: lineNo, outer: emitting, matched: cond.matches(agent)}
+			open = append(open, section)
+			emitting = section.outer && section.matched
+		case "else":
+			if len(open) == 0 {
+				return nil, nil, fmt.Errorf("line %d: gym:else without gym:if", lineNo)
+			}
+			section := &open[len(open)-1]
+			if section.afterElse {
+				return nil, nil, fmt.Errorf("line %d: second gym:else for the gym:if on line %d", lineNo, section.line)
+			}
+			if arg != "" {
+				return nil, nil, fmt.Errorf("line %d: gym:else takes no condition", lineNo)
+			}
+			section.afterElse = true
+			emitting = section.outer && !section.matched
+		case "end":
+			if len(open) == 0 {
+				return nil, nil, fmt.Errorf("line %d: gym:end without gym:if", lineNo)
+			}
+			if arg != "" {
+				return nil, nil, fmt.Errorf("line %d: gym:end takes no condition", lineNo)
+			}
+			emitting = open[len(open)-1].outer
+			open = open[:len(open)-1]
+		default:
+			return nil, nil, fmt.Errorf("line %d: unknown marker gym:%s (want gym:if, gym:else or gym:end)", lineNo, directive)
+		}
+	}
+	if len(open) > 0 {
+		return nil, nil, fmt.Errorf("line %d: gym:if is never closed with gym:end", open[len(open)-1].line)
+	}
+	return out, names, nil
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const nestedSections = `intro
+<!-- gym:if agent=codex,pi -->
+shared
+<!-- gym:if agent!=pi -->
+codex only
+<!-- gym:else -->
+pi only
+<!-- gym:end -->
+<!-- gym:else -->
+others
+<!-- gym:end -->
+outro
+`
+
+func TestResolveAgentSections(t *testing.T) {
+	tests := []struct {
+		agent string
+		want  string
+	}{
+		{"codex", "intro\nshared\ncodex only\noutro\n"},
+		{"pi", "intro\nshared\npi only\noutro\n"},
+		{"claude", "intro\nothers\noutro\n"},
+		{"", "intro\nothers\noutro\n"},
+	}
+	for _, tt := range tests {
+		got, names, err := resolveAgentSections([]byte(nestedSections), tt.agent)
+		if err != nil {
+			t.Fatalf("agent %q: %v", tt.agent, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("agent %q:\n got %q\nwant %q", tt.agent, got, tt.want)
+		}
+		if !slices.Equal(names, []string{"codex", "pi"}) {
+			t.Errorf("agent %q: names = %v, want [codex pi]", tt.agent, names)
+		}
+	}
+}
+
+func TestResolveAgentSectionsCRLF(t *testing.T) {
+	data := "a\r\n<!-- gym:if agent=codex -->  \r\nb\r\n<!-- gym:else -->\r\nc\r\n<!-- gym:end -->\r\nd"
+	got, _, err := resolveAgentSections([]byte(data), "codex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a\r\nb\r\nd"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolveAgentSectionsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unclosed", "x\n<!-- gym:if agent=codex -->\ny\n", "line 2: gym:if is never closed"},
+		{"unclosed inner", "<!-- gym:if agent=codex -->\n<!-- gym:if agent=pi -->\n<!-- gym:end -->\n", "line 1: gym:if is never closed"},
+		{"stray else", "<!-- gym:else -->\n", "line 1: gym:else without gym:if"},
+		{"stray end", "x\n<!-- gym:end -->\n", "line 2: gym:end without gym:if"},
+		{"double else", "<!-- gym:if agent=codex -->\n<!-- gym:else -->\n<!-- gym:else -->\n<!-- gym:end -->\n", "line 3: second gym:else for the gym:if on line 1"},
+		{"else condition", "<!-- gym:if agent=codex -->\n<!-- gym:else agent=pi -->\n<!-- gym:end -->\n", "gym:else takes no condition"},
+		{"bad condition", "<!-- gym:if codex -->\n<!-- gym:end -->\n", "invalid condition"},
+		{"empty agent", "<!-- gym:if agent=codex, -->\n<!-- gym:end -->\n", "empty agent name"},
+		{"unknown marker", "<!-- gym:elif agent=pi -->\n", "unknown marker gym:elif"},
+	}
+	for _, tt := range tests {
+		_, _, err := resolveAgentSections([]byte(tt.data), "codex")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRenderResolvesSectionsBeforeTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"SKILL.md": "---\nname: demo\ntemplates: [\"guide.md\"]\nvars:\n  tool: make\n---\n",
+		"guide.md": "run {{.tool}}\n<!-- gym:if agent=pi -->\nuse {{.piOnly}}\n<!-- gym:else -->\nplain {{.tool}}\n<!-- gym:end -->\n",
+		"notes.md": "<!-- gym:if agent=pi -->\n{{.tool}}\n<!-- gym:end -->\nkept\n",
+	})
+	r, err := newSkillRenderer(skillSource{Dir: dir, Agent: "codex", Vars: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.render("guide.md", filepath.Join(dir, "guide.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "run make\nplain make\n"; string(got) != want {
+		t.Errorf("guide.md: got %q, want %q", got, want)
+	}
+	got, err = r.render("notes.md", filepath.Join(dir, "notes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "kept\n"; string(got) != want {
+		t.Errorf("notes.md: got %q, want %q", got, want)
+	}
+
+	r, err = newSkillRenderer(skillSource{Dir: dir, Agent: "pi", Vars: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.render("guide.md", filepath.Join(dir, "guide.md")); err == nil || !strings.Contains(err.Error(), "piOnly") {
+		t.Errorf("pi render err = %v, want missing piOnly", err)
+	}
+}
+
+func TestAgentSectionsSkipFencedCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		sections bool
+		want     string
+	}{
+		{
+			name: "backtick fence",
+			data: "Write sections like this:\n```markdown\n<!-- gym:if agent=pi -->\npi only\n<!-- gym:end -->\n```\n",
+			want: "Write sections like this:\n```markdown\n<!-- gym:if agent=pi -->\npi only\n<!-- gym:end -->\n```\n",
+		},
+		{
+			name: "tilde fence with a longer close",
+			data: "  ~~~\n<!-- gym:else -->\n~~~~~\n",
+			want: "  ~~~\n<!-- gym:else -->\n~~~~~\n",
+		},
+		{
+			name: "shorter or other fence does not close",
+			data: "````\n```\n~~~\n<!-- gym:end -->\n````\n",
+			want: "````\n```\n~~~\n<!-- gym:end -->\n````\n",
+		},
+		{
+			name:     "sections around a fence",
+			data:     "<!-- gym:if agent=codex -->\n```sh\n<!-- gym:else -->\n```\n<!-- gym:else -->\npi\n<!-- gym:end -->\n",
+			sections: true,
+			want:     "```sh\n<!-- gym:else -->\n```\n",
+		},
+		{
+			name:     "inline code is not a fence",
+			data:     "```go``` is inline\n<!-- gym:if agent=pi -->\npi\n<!-- gym:end -->\n",
+			sections: true,
+			want:     "```go``` is inline\n",
+		},
+		{
+			name:     "indented code is not a fence",
+			data:     "    ```\n<!-- gym:if agent=pi -->\npi\n<!-- gym:end -->\n",
+			sections: true,
+			want:     "    ```\n",
+		},
+		{
+			name: "unclosed fence runs to the end",
+			data: "```\n<!-- gym:if agent=pi -->\n",
+			want: "```\n<!-- gym:if agent=pi -->\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsAgentSections([]byte(tt.data)); got != tt.sections {
+				t.Errorf("containsAgentSections = %v, want %v", got, tt.sections)
+			}
+			got, _, err := resolveAgentSections([]byte(tt.data), "codex")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// globalSetting is a global config key that gym config reads and changes.
+// env names the environment variable overriding it, if any.
+type globalSetting struct {
+	get      func(cfg GlobalConfig) string
+	set      func(cfg *GlobalConfig, value string) error
+	fallback string
+	env      string
+	required bool
+}
+
+// projectSetting is a .skills.yaml key that gym config reads and changes.
+type projectSetting struct {
+	get      func(cfg ProjectConfig) string
+	set      func(cfg *ProjectConfig, value string) error
+	fallback string
+	required bool
+}
+
+var globalSettings = map[string]globalSetting{
+	"skillRepository": {
+		get: func(cfg GlobalConfig) string { return cfg.SkillRepository },
+		set: func(cfg *GlobalConfig, value string) error {
+			repo, err := validateSkillRepository(value)
+			if err != nil {
+				return err
+			}
+			cfg.SkillRepository = repo
+			return nil
+		},
+		env:      envSkillRepository,
+		required: true,
+	},
+	"symlinks": {
+		get: func(cfg GlobalConfig) string { return cfg.Symlinks },
+		set: func(cfg *GlobalConfig, value string) error {
+			if err := validateSymlinkPolicy(value); err != nil {
+				return err
+			}
+			cfg.Symlinks = value
+			return nil
+		},
+		fallback: symlinkPreserve,
+	},
+}
+
+var projectSettings = map[string]projectSetting{
+	"agents": {
+		get: func(cfg ProjectConfig) string { return strings.Join(cfg.Agents, ",") },
+		set: func(cfg *ProjectConfig, value string) error {
+			agents, err := normalizeAgents(strings.Split(value, ","))
+			if err != nil {
+				return err
+			}
+			cfg.Agents = agents
+			return nil
+		},
+		required: true,
+	},
+	"mode": {
+		get: func(cfg ProjectConfig) string { return cfg.Mode },
+		set: func(cfg *ProjectConfig, value string) error {
+			if err := validateInstallSettings(value, ""); err != nil {
+				return err
+			}
+			cfg.Mode = value
+			return nil
+		},
+		fallback: installModeCopy,
+	},
+	"linkStyle": {
+		get: func(cfg ProjectConfig) string { return cfg.LinkStyle },
+		set: func(cfg *ProjectConfig, value string) error {
+			if err := validateInstallSettings("", value); err != nil {
+				return err
+			}
+			cfg.LinkStyle = value
+			return nil
+		},
+		fallback: linkStyleRelative,
+	},
+	"inherit": {
+		get: func(cfg ProjectConfig) string {
+			if cfg.Inherit == nil {
+				return ""
+			}
+			return strconv.FormatBool(*cfg.Inherit)
+		},
+		set: func(cfg *ProjectConfig, value string) error {
+			if value == "" {
+				cfg.Inherit = nil
+				return nil
+			}
+			inherit, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("inherit must be true or false, got %q", value)
+			}
+			cfg.Inherit = &inherit
+			return nil
+		},
+		fallback: "true",
+	},
+}
+
+func settingKeys() []string {
+	keys := append(sortedGlobalKeys(), sortedProjectKeys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+// configScope holds the --global and --local selectors of gym config.
+// With neither set, commands use both scopes.
+type configScope struct {
+	global bool
+	local  bool
+}
+
+// lookupSetting checks that key exists and fits the selected scope. It
+// reports whether the key is a global setting.
+func lookupSetting(key string, scope *configScope) (bool, error) {
+	if _, ok := globalSettings[key]; ok {
+		if scope.local {
+			return false, fmt.Errorf("%s is a global setting; drop --local", key)
+		}
+		return true, nil
+	}
+	if _, ok := projectSettings[key]; ok {
+		if scope.global {
+			return false, fmt.Errorf("%s is a project setting; drop --global", key)
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown config key %q (want one of %s)", key, strings.Join(settingKeys(), ", "))
+}
+
+func configCmd() *cobra.Command {
+	scope := &configScope{}
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change global and project settings",
+		Long: "Read and change global and project settings.\n\n" +
+			"Global keys (" + strings.Join(sortedGlobalKeys(), ", ") + ") live in the global config and\n" +
+			"project keys (" + strings.Join(sortedProjectKeys(), ", ") + ") in the .skills.yaml of the\n" +
+			"current project; --project/-C selects another project. --global and\n" +
+			"--local restrict commands to the global config or the project config.\n" +
+			"The project scope is --local, as in git config, because --project is the\n" +
+			"root flag that picks the project directory.",
+	}
+	cmd.PersistentFlags().BoolVar(&scope.global, "global", false, "use the global config only")
+	cmd.PersistentFlags().BoolVar(&scope.local, "local", false, "use the project config only (the project directory is set with --project/-C)")
+	cmd.MarkFlagsMutuallyExclusive("global", "local")
+	cmd.AddCommand(configGetCmd(scope))
+	cmd.AddCommand(configSetCmd(scope))
+	cmd.AddCommand(configUnsetCmd(scope))
+	cmd.AddCommand(configListCmd(scope))
+	cmd.AddCommand(configEditCmd(scope))
+	return cmd
+}
+
+func sortedGlobalKeys() []string {
+	keys := make([]string, 0, len(globalSettings))
+	for key := range globalSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedProjectKeys() []string {
+	keys := make([]string, 0, len(projectSettings))
+	for key := range projectSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func configGetCmd(scope *configScope) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isGlobal, err := lookupSetting(args[0], scope)
+			if err != nil {
+				return err
+			}
+			var value string
+			if isGlobal {
+				value, _, err = effectiveGlobalSetting(args[0])
+			} else {
+				value, _, err = effectiveProjectSetting(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stdout, value)
+			return nil
+		},
+	}
+}
+
+func configSetCmd(scope *configScope) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Validate and store a setting",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isGlobal, err := lookupSetting(args[0], scope)
+			if err != nil {
+				return err
+			}
+			if args[1] == "" {
+				return fmt.Errorf("value for %s is empty; use gym config unset", args[0])
+			}
+			if isGlobal {
+				return updateGlobalSetting(args[0], args[1])
+			}
+			return updateProjectSetting(args[0], args[1])
+		},
+	}
+}
+
+func configUnsetCmd(scope *configScope) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting so its default applies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isGlobal, err := lookupSetting(args[0], scope)
+			if err != nil {
+				return err
+			}
+			if isGlobal {
+				return updateGlobalSetting(args[0], "")
+			}
+			return updateProjectSetting(args[0], "")
+		},
+	}
+}
+
+func configListCmd(scope *configScope) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Print every setting with its effective value and where it comes from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "KEY\tVALUE\tSOURCE")
+			if !scope.local {
+				for _, key := range sortedGlobalKeys() {
+					value, source, err := effectiveGlobalSetting(key)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(table, "%s\t%s\t%s\n", key, value, source)
+				}
+			}
+			if !scope.global {
+				// Without --local, list works outside projects too.
+				_, err := findProjectRoot()
+				if err != nil && scope.local {
+					return err
+				}
+				if err == nil {
+					for _, key := range sortedProjectKeys() {
+						value, source, err := effectiveProjectSetting(key)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(table, "%s\t%s\t%s\n", key, value, source)
+					}
+				}
+			}
+			return table.Flush()
+		},
+	}
+}
+
+func configEditCmd(scope *configScope) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the project config, or the global config with --global, in $EDITOR",
+		Long: "Open the project config, or the global config with --global, in $VISUAL or\n" +
+			"$EDITOR. The edited file is validated before it replaces the config; if it\n" +
+			"is invalid, the config is left unchanged and the edits are kept in a\n" +
+			"temporary file.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if scope.global {
+				configLock, err := lockGlobalConfig()
+				if err != nil {
+					return err
+				}
+				defer configLock.release()
+				path, err := globalConfigPath()
+				if err != nil {
+					return err
+				}
+				return editConfigFile(path, func(data []byte) error {
+					cfg, err := parseGlobalConfig(path, data)
+					if err != nil {
+						return err
+					}
+					if cfg.SkillRepository != "" {
+						if _, err := validateSkillRepository(cfg.SkillRepository); err != nil {
+							return fmt.Errorf("global config %s: %w", path, err)
+						}
+					}
+					return nil
+				})
+			}
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			stateLock, err := lockProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			defer stateLock.release()
+			path := filepath.Join(projectRoot, projectConfigName)
+			return editConfigFile(path, func(data []byte) error {
+				cfg, err := parseProjectConfig(path, data)
+				if err != nil {
+					return err
+				}
+				return ensureSupportedAgents(cfg.Agents)
+			})
+		},
+	}
+}
+
+// effectiveGlobalSetting returns the value gym uses for a global key and
+// where it comes from.
+func effectiveGlobalSetting(key string) (string, string, error) {
+	setting := globalSettings[key]
+	if setting.env != "" {
+		if value := os.Getenv(setting.env); value != "" {
+			return value, "env " + setting.env, nil
+		}
+	}
+	cfg, err := readGlobalConfigFile()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", "", err
+	}
+	if value := setting.get(cfg); value != "" {
+		path, err := globalConfigPath()
+		if err != nil {
+			return "", "", err
+		}
+		return value, path, nil
+	}
+	if setting.fallback == "" {
+		return "", "unset", nil
+	}
+	return setting.fallback, "default", nil
+}
+
+// effectiveProjectSetting returns the merged value of a project key and the
+// config files that set it.
+func effectiveProjectSetting(key string) (string, string, error) {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return "", "", err
+	}
+	layers, err := projectConfigChain(projectRoot)
+	if err != nil {
+		return "", "", err
+	}
+	setting := projectSettings[key]
+	sources := make([]string, 0)
+	for _, layer := range layers {
+		if setting.get(layer.Config) != "" {
+			sources = append(sources, filepath.Join(layer.Dir, projectConfigName))
+		}
+	}
+	value := setting.get(mergeProjectConfigs(layers))
+	if len(sources) == 0 {
+		if setting.fallback == "" {
+			return "", "unset", nil
+		}
+		return setting.fallback, "default", nil
+	}
+	if key == "inherit" {
+		// Inherit only applies to the file that sets it.
+		value = setting.get(layers[len(layers)-1].Config)
+		if value == "" {
+			value = setting.fallback
+		}
+	}
+	return value, strings.Join(sources, ", "), nil
+}
+
+// updateGlobalSetting sets a global key, or removes it when value is empty.
+// A missing global config file is created.
+func updateGlobalSetting(key, value string) error {
+	setting := globalSettings[key]
+	if value == "" && setting.required {
+		return fmt.Errorf("%s is required and cannot be unset", key)
+	}
+	configLock, err := lockGlobalConfig()
+	if err != nil {
+		return err
+	}
+	defer configLock.release()
+	cfg, err := readGlobalConfigFile()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := setting.set(&cfg, value); err != nil {
+		return fmt.Errorf("set %s: %w", key, err)
+	}
+	if err := writeGlobalConfig(cfg); err != nil {
+		return err
+	}
+	if setting.env != "" && os.Getenv(setting.env) != "" {
+		fmt.Fprintf(os.Stdout, "Note: %s is set and overrides %s\n", setting.env, key)
+	}
+	return nil
+}
+
+// updateProjectSetting sets a key in the project's own .skills.yaml, or
+// removes it when value is empty. Inherited configs are left alone.
+func updateProjectSetting(key, value string) error {
+	setting := projectSettings[key]
+	if value == "" && setting.required {
+		return fmt.Errorf("%s is required and cannot be unset", key)
+	}
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	stateLock, err := lockProject(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer stateLock.release()
+	cfg, err := readProjectConfigFile(projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := setting.set(&cfg, value); err != nil {
+		return fmt.Errorf("set %s: %w", key, err)
+	}
+	if err := writeProjectConfig(projectRoot, cfg); err != nil {
+		return err
+	}
+	if key == "agents" {
+		fmt.Fprintln(os.Stdout, "Run gym sync to install skills for added agents")
+	}
+	return nil
+}
+
+// editConfigFile lets the user edit a copy of path and replaces path with
+// it once validate accepts the result.
+func editConfigFile(path string, validate func(data []byte) error) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	tmp, err := os.CreateTemp("", "gym-*.yaml")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write %s: %w", tmpPath, err)
+	}
+
+	editor := strings.Fields(configEditor())
+	edit := exec.Command(editor[0], append(editor[1:], tmpPath)...)
+	edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := edit.Run(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("run editor %s: %w", editor[0], err)
+	}
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", tmpPath, err)
+	}
+	if bytes.Equal(edited, original) {
+		os.Remove(tmpPath)
+		fmt.Fprintf(os.Stdout, "%s unchanged\n", path)
+		return nil
+	}
+	if err := validate(edited); err != nil {
+		return fmt.Errorf("%w\nnot saved; your edits are in %s", err, tmpPath)
+	}
+	if err := writeFileAtomic(path, edited, 0o644); err != nil {
+		return err
+	}
+	os.Remove(tmpPath)
+	fmt.Fprintf(os.Stdout, "Updated %s\n", path)
+	return nil
+}
+
+func configEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigCommands(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	globalPath, err := globalConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(base, "project")
+	setupTestProject(t, project)
+	projectPath := filepath.Join(project, projectConfigName)
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	steps := []struct {
+		args   []string
+		output string
+		err    string
+	}{
+		{args: []string{"get", "mode"}, output: "copy\n"},
+		{args: []string{"set", "mode", "link", "--local"}},
+		{args: []string{"get", "mode"}, output: "link\n"},
+		{args: []string{"set", "mode", "sideways"}, err: `set mode: unsupported mode "sideways"`},
+		{args: []string{"set", "mode", "link", "--global"}, err: "mode is a project setting; drop --global"},
+		{args: []string{"get", "symlinks", "--local"}, err: "symlinks is a global setting; drop --local"},
+		{args: []string{"get", "colour"}, err: `unknown config key "colour" (want one of agents, inherit, linkStyle, mode, skillRepository, symlinks)`},
+		{args: []string{"set", "mode", ""}, err: "value for mode is empty; use gym config unset"},
+		{args: []string{"set", "agents", "pi,codex"}, output: "Run gym sync to install skills for added agents\n"},
+		{args: []string{"set", "symlinks", "reject", "--global"}},
+		{args: []string{"unset", "agents"}, err: "agents is required and cannot be unset"},
+		{args: []string{"unset", "skillRepository"}, err: "skillRepository is required and cannot be unset"},
+		{
+			args: []string{"list"},
+			output: "KEY VALUE SOURCE\n" +
+				"skillRepository " + repo.Dir + " " + globalPath + "\n" +
+				"symlinks reject " + globalPath + "\n" +
+				"agents pi,codex " + projectPath + "\n" +
+				"inherit true default\n" +
+				"linkStyle relative default\n" +
+				"mode link " + projectPath + "\n",
+		},
+		{args: []string{"unset", "mode"}},
+		{args: []string{"unset", "symlinks"}},
+		{
+			args: []string{"list", "--global"},
+			output: "KEY VALUE SOURCE\n" +
+				"skillRepository " + repo.Dir + " " + globalPath + "\n" +
+				"symlinks preserve default\n",
+		},
+		{
+			args: []string{"list", "--local"},
+			output: "KEY VALUE SOURCE\n" +
+				"agents pi,codex " + projectPath + "\n" +
+				"inherit true default\n" +
+				"linkStyle relative default\n" +
+				"mode copy default\n",
+		},
+	}
+	for _, step := range steps {
+		output, err := runCommand(t, configCmd(), step.args...)
+		name := strings.Join(step.args, " ")
+		switch {
+		case step.err == "" && err != nil:
+			t.Fatalf("%s: %v", name, err)
+		case step.err != "" && (err == nil || !strings.HasPrefix(err.Error(), step.err)):
+			t.Fatalf("%s = %v, want error %q", name, err, step.err)
+		case normalizeColumns(output) != step.output:
+			t.Errorf("%s printed:\n%s\nwant:\n%s", name, output, step.output)
+		}
+	}
+
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mode != "" || strings.Join(cfg.Agents, ",") != "pi,codex" {
+		t.Errorf("project config = %+v", cfg)
+	}
+}
+
+func TestConfigSetSkillRepository(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, base, map[string]string{"other/beta/SKILL.md": "beta\n", "file": "x\n"})
+	t.Chdir(base)
+
+	tests := []struct {
+		value string
+		want  string
+		err   string
+	}{
+		{value: "missing", err: "set skillRepository: stat skill repository"},
+		{value: "file", err: "set skillRepository: skill repository file is not a directory"},
+		{value: "other", want: filepath.Join(base, "other")},
+		{value: repo.Dir, want: repo.Dir},
+	}
+	for _, tt := range tests {
+		_, err := runCommand(t, configCmd(), "set", "skillRepository", tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("set %s = %v, want error %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("set %s: %v", tt.value, err)
+		}
+		cfg, err := readGlobalConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.SkillRepository != tt.want {
+			t.Errorf("set %s stored %s, want %s", tt.value, cfg.SkillRepository, tt.want)
+		}
+	}
+}
+
+func TestConfigEnvironmentOverrides(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, base, map[string]string{"ci/alpha/SKILL.md": "alpha\n"})
+	ciRepo := filepath.Join(base, "ci")
+
+	t.Setenv(envSkillRepository, ciRepo)
+	output, err := runCommand(t, configCmd(), "list", "--global")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(normalizeColumns(output), "skillRepository "+ciRepo+" env "+envSkillRepository+"\n") {
+		t.Errorf("list does not show the override:\n%s", output)
+	}
+	if cfg, err := loadGlobalConfig(); err != nil || cfg.SkillRepository != ciRepo {
+		t.Errorf("loadGlobalConfig = %+v, %v; want repository %s", cfg, err, ciRepo)
+	}
+	output, err = runCommand(t, configCmd(), "set", "skillRepository", repo.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Note: "+envSkillRepository+" is set and overrides skillRepository\n" {
+		t.Errorf("set printed %q", output)
+	}
+	t.Setenv(envSkillRepository, "")
+
+	// GYM_CONFIG moves the whole global config; the default file is left
+	// alone.
+	defaultPath, err := globalConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := filepath.Join(base, "ci", "gym.yaml")
+	t.Setenv(envConfig, custom)
+	if path, err := globalConfigPath(); err != nil || path != custom {
+		t.Fatalf("globalConfigPath = %s, %v; want %s", path, err, custom)
+	}
+	if _, err := runCommand(t, configCmd(), "set", "skillRepository", ciRepo); err != nil {
+		t.Fatal(err)
+	}
+	output, err = runCommand(t, configCmd(), "get", "skillRepository")
+	if err != nil || output != ciRepo+"\n" {
+		t.Errorf("get = %q, %v; want %s", output, err, ciRepo)
+	}
+	if data, _ := os.ReadFile(defaultPath); strings.Contains(string(data), ciRepo) {
+		t.Errorf("set changed %s:\n%s", defaultPath, data)
+	}
+}
+
+// normalizeColumns collapses the padding of table output, which depends on
+// the length of temporary paths.
+func normalizeColumns(output string) string {
+	lines := strings.SplitAfter(output, "\n")
+	for i, line := range lines {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines[i] = strings.Join(fields, " ") + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -27,6 +28,10 @@ func listSupportedAgents() []string {
 	return agents
 }
 
+// stdinReader is shared by all prompts so input read ahead by one prompt,
+// such as piped answers, is not lost to the next.
+var stdinReader = bufio.NewReader(os.Stdin)
+
 func promptAgents(r io.Reader, w io.Writer) ([]string, error) {
 	agents := listSupportedAgents()
 	fmt.Fprintln(w, "Select agents used in this project:")
@@ -79,7 +84,12 @@ func promptSkillRepository(r io.Reader, w io.Writer) (string, error) {
 	if err != nil && !errors.Is(err, io.EOF) {
 		return "", fmt.Errorf("read repository path: %w", err)
 	}
-	repo := strings.TrimSpace(line)
+	return validateSkillRepository(strings.TrimSpace(line))
+}
+
+// validateSkillRepository checks that repo is an existing directory and
+// returns its absolute path.
+func validateSkillRepository(repo string) (string, error) {
 	if repo == "" {
 		return "", errors.New("skill repository path is empty")
 	}
@@ -90,7 +100,25 @@ func promptSkillRepository(r io.Reader, w io.Writer) (string, error) {
 	if !info.IsDir() {
 		return "", fmt.Errorf("skill repository %s is not a directory", repo)
 	}
-	return repo, nil
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return "", fmt.Errorf("resolve skill repository %s: %w", repo, err)
+	}
+	return abs, nil
+}
+
+func promptConfirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N]: ", question)
+	reader := bufio.NewReader(r)
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
 }
 
 func parsePositiveInt(value string) (int, error) {
@@ -114,6 +142,45 @@ func ensureSupportedAgents(agents []string) error {
 	return nil
 }
 
+// normalizeAgents trims and de-duplicates a list of agent names given on
+// the command line and checks that each is supported.
+func normalizeAgents(names []string) ([]string, error) {
+	agents := make([]string, 0, len(names))
+	for _, agent := range names {
+		agent = strings.TrimSpace(agent)
+		if agent != "" && !slices.Contains(agents, agent) {
+			agents = append(agents, agent)
+		}
+	}
+	if len(agents) == 0 {
+		return nil, errors.New("agents list is empty")
+	}
+	if err := ensureSupportedAgents(agents); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// detectAgents returns the agents whose directory, such as .codex, already
+// exists in projectRoot.
+func detectAgents(projectRoot string) ([]string, error) {
+	detected := make([]string, 0)
+	for _, agent := range listSupportedAgents() {
+		dir := strings.Split(filepath.ToSlash(supportedAgents[agent]), "/")[0]
+		info, err := os.Stat(filepath.Join(projectRoot, dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if info.IsDir() {
+			detected = append(detected, agent)
+		}
+	}
+	return detected, nil
+}
+
 func defaultSkillDir(agent string) (string, error) {
 	dir, ok := supportedAgents[agent]
 	if !ok {
@@ -122,54 +189,193 @@ func defaultSkillDir(agent string) (string, error) {
 	return dir, nil
 }
 
-func copySkillDir(src, dst string) error {
-	info, err := os.Stat(src)
+// placeFileFunc materializes a single regular file of a skill at dst.
+type placeFileFunc func(src, dst string, mode fs.FileMode) error
+
+// filePlacement is how an install mode places files. Shared placements
+// leave the target on the inode of the repository file where they can.
+type filePlacement struct {
+	place  placeFileFunc
+	shared bool
+}
+
+var (
+	copyPlacement     = filePlacement{place: copyFile}
+	hardlinkPlacement = filePlacement{place: hardlinkFile, shared: true}
+	reflinkPlacement  = filePlacement{place: reflinkFile}
+)
+
+func copySkillDir(src skillSource, dst string) error {
+	return copySkillDirWith(src, dst, copyPlacement)
+}
+
+// copySkillDirWith brings dst in line with src, touching only entries that
+// differ: identical files keep their mtimes, permission-only changes are
+// fixed with chmod and entries missing from src are deleted.
+func copySkillDirWith(src skillSource, dst string, placement filePlacement) error {
+	info, err := os.Stat(src.Dir)
 	if err != nil {
-		return fmt.Errorf("stat source %s: %w", src, err)
+		return fmt.Errorf("stat source %s: %w", src.Dir, err)
 	}
 	if !info.IsDir() {
-		return fmt.Errorf("source %s is not a directory", src)
+		return fmt.Errorf("source %s is not a directory", src.Dir)
 	}
 
-	if err := os.RemoveAll(dst); err != nil {
-		return fmt.Errorf("remove existing %s: %w", dst, err)
+	if dstInfo, err := os.Lstat(dst); err == nil && !dstInfo.IsDir() {
+		if err := os.RemoveAll(dst); err != nil {
+			return fmt.Errorf("remove existing %s: %w", dst, err)
+		}
 	}
 	if err := os.MkdirAll(dst, info.Mode()); err != nil {
 		return fmt.Errorf("create destination %s: %w", dst, err)
 	}
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("chmod destination %s: %w", dst, err)
+	}
 
-	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == src {
+	names := map[string]bool{}
+	if err := src.walk(func(entry skillEntry) error {
+		names[entry.Rel] = true
+		return syncEntry(entry, filepath.Join(dst, entry.Rel), placement)
+	}); err != nil {
+		return err
+	}
+
+	return pruneExtraEntries(names, dst)
+}
+
+// syncEntry brings target in line with one source entry. Rendered templates
+// are always written as new files, whatever the install mode.
+//
+// A target that shares its inode with the repository file is replaced
+// unless the placement is shared, so switching away from hardlink mode
+// stops project edits from writing through. With a shared placement an
+// equal copy is re-linked, and a linked target is never chmodded: it
+// carries the repository file's mode, and chmod would change both.
+func syncEntry(entry skillEntry, target string, placement filePlacement) error {
+	mode := entry.Info.Mode()
+	targetInfo, err := os.Lstat(target)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	switch {
+	case mode.IsDir():
+		if exists && targetInfo.IsDir() {
+			if targetInfo.Mode().Perm() != mode.Perm() {
+				return os.Chmod(target, mode.Perm())
+			}
 			return nil
 		}
-		rel, err := filepath.Rel(src, path)
+	case mode&os.ModeSymlink != 0:
+		link, err := os.Readlink(entry.Path)
 		if err != nil {
 			return err
 		}
-		target := filepath.Join(dst, rel)
-		info, err := d.Info()
-		if err != nil {
+		if exists && targetInfo.Mode()&os.ModeSymlink != 0 {
+			if current, err := os.Readlink(target); err == nil && current == link {
+				return nil
+			}
+		}
+		if err := os.RemoveAll(target); err != nil {
 			return err
 		}
-
-		if d.IsDir() {
-			return os.MkdirAll(target, info.Mode())
+		return os.Symlink(link, target)
+	default:
+		if exists && targetInfo.Mode().IsRegular() {
+			shared := entry.Content == nil && os.SameFile(entry.Info, targetInfo)
+			if shared && placement.shared {
+				return nil
+			}
+			if !shared {
+				equal, err := entryContentEqual(entry, target)
+				if err != nil {
+					return err
+				}
+				if equal {
+					if placement.shared && entry.Content == nil {
+						if linked, err := relinkFile(entry.Path, target); err != nil || linked {
+							return err
+						}
+					}
+					if targetInfo.Mode().Perm() != mode.Perm() {
+						return os.Chmod(target, mode.Perm())
+					}
+					return nil
+				}
+			}
 		}
+	}
 
-		if info.Mode()&os.ModeSymlink != 0 {
-			link, err := os.Readlink(path)
-			if err != nil {
-				return err
-			}
-			return os.Symlink(link, target)
+	if exists {
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+	}
+	if mode.IsDir() {
+		if err := os.MkdirAll(target, mode); err != nil {
+			return err
 		}
+		// MkdirAll applies the umask.
+		return os.Chmod(target, mode.Perm())
+	}
+	placeFile := placement.place
+	if entry.Content != nil {
+		placeFile = func(_, dst string, mode fs.FileMode) error {
+			return writeFileContent(dst, entry.Content, mode)
+		}
+	}
+	if err := placeFile(entry.Path, target, mode); err != nil {
+		return err
+	}
+	placed, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	if placed.Mode().Perm() != mode.Perm() {
+		return os.Chmod(target, mode.Perm())
+	}
+	return nil
+}
 
-		if err := copyFile(path, target, info.Mode()); err != nil {
+// relinkFile replaces dst with a hard link to src and reports whether it
+// could. A filesystem without hard links leaves the existing copy alone.
+func relinkFile(src, dst string) (bool, error) {
+	tmp := dst + ".gym-link"
+	if err := os.Link(src, tmp); err != nil {
+		return false, nil
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+// pruneExtraEntries deletes entries under dst whose relative path is not
+// in names.
+func pruneExtraEntries(names map[string]bool, dst string) error {
+	return filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
 			return err
 		}
+		if path == dst {
+			return nil
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil {
+			return err
+		}
+		if names[rel] {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
 		return nil
 	})
 }
@@ -196,31 +402,37 @@ func copyFile(src, dst string, mode fs.FileMode) error {
 	return nil
 }
 
+func writeFileContent(dst string, content []byte, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, content, mode)
+}
+
 func resolveSkillTarget(projectRoot, skillName, agent string, overrides map[string]string) (string, error) {
-	if overrides != nil {
-		if override, ok := overrides[agent]; ok && override != "" {
-			return filepath.Join(projectRoot, override), nil
+	if err := validateSkillName(skillName); err != nil {
+		return "", err
+	}
+	target := ""
+	if override, ok := overrides[agent]; ok && override != "" {
+		if err := validateTargetOverride(agent, override); err != nil {
+			return "", err
+		}
+		target = filepath.Join(projectRoot, override)
+	} else {
+		baseDir, err := defaultSkillDir(agent)
+		if err != nil {
+			return "", err
 		}
+		target = filepath.Join(projectRoot, baseDir, skillName)
 	}
-	baseDir, err := defaultSkillDir(agent)
-	if err != nil {
+	if err := ensureWithin(projectRoot, target); err != nil {
 		return "", err
 	}
-	return filepath.Join(projectRoot, baseDir, skillName), nil
+	return target, nil
 }
 
 func dirHasSkillFile(dir string) (bool, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return false, err
-	}
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if strings.EqualFold(entry.Name(), "skill.md") {
-			return true, nil
-		}
-	}
-	return false, nil
+	path, err := findSkillFile(dir)
+	return path != "", err
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopySkillDirAppliesDirectoryModes(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	src := filepath.Join(base, "repo", "alpha")
+	dst := filepath.Join(base, "project", "alpha")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "SKILL.md"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copySkillDir(plainSource(src), dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{src, filepath.Join(src, "sub")} {
+		if err := os.Chmod(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		equal, err := dirsEqual(plainSource(src), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if equal {
+			t.Errorf("dirsEqual ignores the mode of %s", dir)
+		}
+		if err := copySkillDir(plainSource(src), dst); err != nil {
+			t.Fatal(err)
+		}
+		rel, _ := filepath.Rel(src, dir)
+		info, err := os.Stat(filepath.Join(dst, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != 0o700 {
+			t.Errorf("%s has mode %o after sync, want 700", filepath.Join(dst, rel), got)
+		}
+		if equal, err := dirsEqual(plainSource(src), dst); err != nil || !equal {
+			t.Errorf("dirsEqual after sync = %v, %v; want true", equal, err)
+		}
+	}
+}
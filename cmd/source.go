@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	symlinkPreserve    = "preserve"
+	symlinkDereference = "dereference"
+	symlinkReject      = "reject"
+)
+
+func validateSymlinkPolicy(policy string) error {
+	switch policy {
+	case "", symlinkPreserve, symlinkDereference, symlinkReject:
+		return nil
+	}
+	return fmt.Errorf("unsupported symlinks policy %q (want %s, %s or %s)", policy, symlinkPreserve, symlinkDereference, symlinkReject)
+}
+
+// skillRepository is the central skill repository together with the rules
+// for reading skills out of it.
+type skillRepository struct {
+	Dir      string
+	Symlinks string
+}
+
+func (cfg GlobalConfig) repository() skillRepository {
+	policy := cfg.Symlinks
+	if policy == "" {
+		policy = symlinkPreserve
+	}
+	return skillRepository{Dir: cfg.SkillRepository, Symlinks: policy}
+}
+
+func (r skillRepository) skill(skillName string) skillSource {
+	return skillSource{Dir: filepath.Join(r.Dir, skillName), Symlinks: r.Symlinks, Repository: r.Dir}
+}
+
+// skillSource is a directory whose contents get installed, copied or
+// compared, read according to a symlink policy. Sources inside a
+// repository also honour its .gymignore files, and Agent and Filter narrow
+// the contents for a single agent, resolving the agent sections of its
+// markdown files. Vars, when set, renders the files the skill marks as
+// templates.
+type skillSource struct {
+	Dir        string
+	Symlinks   string
+	Repository string
+	Agent      string
+	Filter     AgentFilter
+	Vars       map[string]string
+}
+
+// plainSource reads dir as it is on disk, keeping symlinks as links. It is
+// used for installed targets.
+func plainSource(dir string) skillSource {
+	return skillSource{Dir: dir, Symlinks: symlinkPreserve}
+}
+
+func (s skillSource) forAgent(agent string, filter AgentFilter) skillSource {
+	s.Agent = agent
+	s.Filter = filter
+	return s
+}
+
+// ignoreFiles returns the .gymignore files that apply to the source.
+func (s skillSource) ignoreFiles() []string {
+	if s.Repository == "" {
+		return nil
+	}
+	return []string{filepath.Join(s.Repository, ignoreFileName), filepath.Join(s.Dir, ignoreFileName)}
+}
+
+// skillEntry is one entry below a skill source. Path is where the content
+// is read from and Info describes the entry after applying the symlink
+// policy, so a dereferenced link reports the file or directory it points to.
+// Content holds the rendered bytes of a template and is nil for every other
+// entry, whose content is read from Path.
+type skillEntry struct {
+	Rel     string
+	Path    string
+	Info    fs.FileInfo
+	Content []byte
+}
+
+// walk calls fn for every entry below the source in lexical order, the way
+// the entries end up in an installed copy.
+func (s skillSource) walk(fn func(entry skillEntry) error) error {
+	info, err := os.Stat(s.Dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", s.Dir)
+	}
+	root, err := filepath.EvalSymlinks(s.Dir)
+	if err != nil {
+		return err
+	}
+	w := &sourceWalker{src: s, fn: fn, active: map[string]bool{root: true}}
+	if err := w.loadRules(); err != nil {
+		return err
+	}
+	return w.walkDir(s.Dir, "", len(w.include.patterns) == 0)
+}
+
+type sourceWalker struct {
+	src    skillSource
+	fn     func(entry skillEntry) error
+	active map[string]bool
+
+	repoIgnore  ignoreRules
+	skillIgnore ignoreRules
+	exclude     ignoreRules
+	include     ignoreRules
+	renderer    *skillRenderer
+
+	// pending holds directories that are only reported once an included
+	// entry below them is found, so filtering never leaves empty directories.
+	pending []skillEntry
+}
+
+func (w *sourceWalker) loadRules() error {
+	var err error
+	if w.src.Repository != "" {
+		base, relErr := filepath.Rel(w.src.Repository, w.src.Dir)
+		if relErr != nil {
+			return relErr
+		}
+		w.repoIgnore, err = loadIgnoreFile(filepath.Join(w.src.Repository, ignoreFileName), filepath.ToSlash(base))
+		if err != nil {
+			return err
+		}
+		w.skillIgnore, err = loadIgnoreFile(filepath.Join(w.src.Dir, ignoreFileName), "")
+		if err != nil {
+			return err
+		}
+	}
+	if w.exclude, err = compileGlobs(w.src.Filter.Exclude); err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+	if w.include, err = compileGlobs(w.src.Filter.Include); err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+	w.renderer, err = newSkillRenderer(w.src)
+	return err
+}
+
+// skip reports whether .gymignore files or the agent's excludes drop rel.
+// The skill's own .gymignore takes precedence over the repository's.
+func (w *sourceWalker) skip(rel string, isDir bool) bool {
+	ignored, _ := w.repoIgnore.match(rel, isDir)
+	if skillIgnored, matched := w.skillIgnore.match(rel, isDir); matched {
+		ignored = skillIgnored
+	}
+	if ignored {
+		return true
+	}
+	excluded, _ := w.exclude.match(rel, isDir)
+	return excluded
+}
+
+func (w *sourceWalker) emit(entry skillEntry) error {
+	for _, dir := range w.pending {
+		if err := w.fn(dir); err != nil {
+			return err
+		}
+	}
+	w.pending = w.pending[:0]
+	return w.fn(entry)
+}
+
+// walkDir walks one directory. included is true when the agent's include
+// globs select everything below dir.
+func (w *sourceWalker) walkDir(dir, relDir string, included bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range entries {
+		if relDir == "" && w.src.Repository != "" && d.Name() == ignoreFileName {
+			continue
+		}
+		path := filepath.Join(dir, d.Name())
+		rel := filepath.Join(relDir, d.Name())
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		resolved := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			switch w.src.Symlinks {
+			case symlinkReject:
+				escapes, err := symlinkEscapes(w.src.Dir, path)
+				if err != nil {
+					return err
+				}
+				if escapes {
+					return fmt.Errorf("symlink %s escapes the skill directory", path)
+				}
+			case symlinkDereference:
+				resolved, info, err = dereference(path)
+				if err != nil {
+					return err
+				}
+				path = resolved
+			}
+		}
+
+		slashRel := filepath.ToSlash(rel)
+		if w.skip(slashRel, info.IsDir()) {
+			continue
+		}
+		entryIncluded := included
+		if !entryIncluded {
+			entryIncluded, _ = w.include.match(slashRel, info.IsDir())
+		}
+		entry := skillEntry{Rel: rel, Path: path, Info: info}
+		if !info.IsDir() {
+			if entryIncluded {
+				if w.renderer != nil && info.Mode().IsRegular() {
+					if entry.Content, err = w.renderer.render(slashRel, path); err != nil {
+						return err
+					}
+				}
+				if err := w.emit(entry); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if resolved != "" {
+			if w.active[resolved] {
+				return fmt.Errorf("symlink %s creates a cycle", filepath.Join(dir, d.Name()))
+			}
+			w.active[resolved] = true
+		}
+		depth := len(w.pending) + 1
+		if entryIncluded {
+			err = w.emit(entry)
+		} else {
+			w.pending = append(w.pending, entry)
+		}
+		if err == nil {
+			err = w.walkDir(path, rel, entryIncluded)
+		}
+		if !entryIncluded && len(w.pending) == depth {
+			w.pending = w.pending[:depth-1]
+		}
+		if resolved != "" {
+			delete(w.active, resolved)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dereference resolves a symlink to the path and info of what it points to.
+func dereference(path string) (string, fs.FileInfo, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("symlink %s is dangling", path)
+		}
+		return "", nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", nil, err
+	}
+	return resolved, info, nil
+}
+
+// symlinkEscapes reports whether the link at path is absolute or points
+// outside skillDir, in which case it would not resolve inside a project.
+func symlinkEscapes(skillDir, path string) (bool, error) {
+	link, err := os.Readlink(path)
+	if err != nil {
+		return false, err
+	}
+	if filepath.IsAbs(link) || filepath.VolumeName(link) != "" {
+		return true, nil
+	}
+	return !isWithin(skillDir, filepath.Join(filepath.Dir(path), link), true), nil
+}
+
+// entryNames returns the relative paths of every entry below the source.
+func (s skillSource) entryNames() (map[string]bool, error) {
+	names := map[string]bool{}
+	err := s.walk(func(entry skillEntry) error {
+		names[entry.Rel] = true
+		return nil
+	})
+	return names, err
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupSymlinkSkill creates a skill with a link inside the skill and a link
+// to a file shared by the whole repository.
+func setupSymlinkSkill(t *testing.T) string {
+	t.Helper()
+	repoDir := t.TempDir()
+	writeTestFiles(t, repoDir, map[string]string{
+		"shared/style.md":        "style\n",
+		"demo/SKILL.md":          "demo\n",
+		"demo/docs/guide.md":     "guide\n",
+		"demo/scripts/run.sh":    "run\n",
+		"other/SKILL.md":         "other\n",
+		"other/docs/internal.md": "internal\n",
+	})
+	links := map[string]string{
+		"demo/guide.md":  filepath.Join("docs", "guide.md"),
+		"demo/style.md":  filepath.Join("..", "shared", "style.md"),
+		"other/bin":      "scripts",
+		"other/scripts":  filepath.Join("..", "demo", "scripts"),
+		"other/readme":   filepath.Join("docs", "internal.md"),
+		"other/dangling": "missing.md",
+	}
+	for rel, link := range links {
+		if err := os.Symlink(link, filepath.Join(repoDir, filepath.FromSlash(rel))); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+	return repoDir
+}
+
+func TestSkillSourceSymlinkPolicies(t *testing.T) {
+	repoDir := setupSymlinkSkill(t)
+	tests := []struct {
+		policy string
+		// want maps entry names to "link", "file" or "dir".
+		want map[string]string
+		err  string
+	}{
+		{
+			policy: symlinkPreserve,
+			want:   map[string]string{"guide.md": "link", "style.md": "link", "docs": "dir", "docs/guide.md": "file"},
+		},
+		{
+			policy: symlinkDereference,
+			want:   map[string]string{"guide.md": "file", "style.md": "file", "docs": "dir", "docs/guide.md": "file"},
+		},
+		{
+			policy: symlinkReject,
+			err:    "symlink " + filepath.Join(repoDir, "demo", "style.md") + " escapes the skill directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			src := skillRepository{Dir: repoDir, Symlinks: tt.policy}.skill("demo")
+			got := map[string]string{}
+			paths := map[string]string{}
+			err := src.walk(func(entry skillEntry) error {
+				kind := "file"
+				switch {
+				case entry.Info.Mode()&os.ModeSymlink != 0:
+					kind = "link"
+				case entry.Info.IsDir():
+					kind = "dir"
+				}
+				got[filepath.ToSlash(entry.Rel)] = kind
+				paths[filepath.ToSlash(entry.Rel)] = entry.Path
+				return nil
+			})
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("walk = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, kind := range tt.want {
+				if got[name] != kind {
+					t.Errorf("%s is %q, want %q", name, got[name], kind)
+				}
+			}
+			if tt.policy == symlinkDereference {
+				// Dereferenced entries are read from what the link points to.
+				style, _ := filepath.EvalSymlinks(filepath.Join(repoDir, "shared", "style.md"))
+				if paths["style.md"] != style {
+					t.Errorf("style.md is read from %s, want %s", paths["style.md"], style)
+				}
+			}
+		})
+	}
+}
+
+func TestSkillSourceRejectAllowsLinksInsideSkill(t *testing.T) {
+	repoDir := t.TempDir()
+	writeTestFiles(t, repoDir, map[string]string{"demo/SKILL.md": "demo\n", "demo/docs/guide.md": "guide\n"})
+	if err := os.Symlink(filepath.Join("docs", "guide.md"), filepath.Join(repoDir, "demo", "guide.md")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	src := skillRepository{Dir: repoDir, Symlinks: symlinkReject}.skill("demo")
+	names, err := src.entryNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !names["guide.md"] {
+		t.Errorf("entries = %v, want guide.md", names)
+	}
+}
+
+func TestSkillSourceDereferenceCycle(t *testing.T) {
+	repoDir := t.TempDir()
+	writeTestFiles(t, repoDir, map[string]string{"demo/SKILL.md": "demo\n", "demo/docs/guide.md": "guide\n"})
+	loop := filepath.Join(repoDir, "demo", "docs", "loop")
+	if err := os.Symlink("..", loop); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	src := skillRepository{Dir: repoDir, Symlinks: symlinkDereference}.skill("demo")
+	err := src.walk(func(skillEntry) error { return nil })
+	if err == nil || err.Error() != "symlink "+loop+" creates a cycle" {
+		t.Errorf("walk = %v, want cycle error", err)
+	}
+
+	// Preserved links are never followed, so the cycle is harmless.
+	src.Symlinks = symlinkPreserve
+	if _, err := src.entryNames(); err != nil {
+		t.Errorf("walk with preserve = %v", err)
+	}
+}
+
+func TestSymlinkEscapes(t *testing.T) {
+	repoDir := setupSymlinkSkill(t)
+	abs := filepath.Join(repoDir, "demo", "abs.md")
+	if err := os.Symlink(filepath.Join(repoDir, "shared", "style.md"), abs); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		escapes bool
+	}{
+		{"demo/guide.md", false},
+		{"demo/style.md", true},
+		{"demo/abs.md", true},
+		{"other/bin", false},
+		{"other/scripts", true},
+		{"other/dangling", false},
+	}
+	for _, tt := range tests {
+		skillDir := filepath.Join(repoDir, strings.SplitN(tt.path, "/", 2)[0])
+		escapes, err := symlinkEscapes(skillDir, filepath.Join(repoDir, filepath.FromSlash(tt.path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if escapes != tt.escapes {
+			t.Errorf("symlinkEscapes(%s) = %v, want %v", tt.path, escapes, tt.escapes)
+		}
+	}
+}
+
+func TestLintSymlinks(t *testing.T) {
+	repoDir := setupSymlinkSkill(t)
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{symlinkPreserve, []string{
+			"other/dangling: error: symlink is dangling",
+			"other/scripts: warning: symlink points outside the skill and will not resolve in projects",
+		}},
+		{symlinkDereference, []string{
+			"other/dangling: error: symlink is dangling",
+		}},
+		{symlinkReject, []string{
+			"other/dangling: error: symlink is dangling",
+			"other/scripts: error: symlink escapes the skill directory",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			repo := skillRepository{Dir: repoDir, Symlinks: tt.policy}
+			issues, err := lintSymlinks("other", repo.skill("other"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, issue := range issues {
+				level := "error"
+				if issue.warning {
+					level = "warning"
+				}
+				got = append(got, "other/"+filepath.ToSlash(issue.path)+": "+level+": "+issue.message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRejectPolicyFailsLintAndInstall(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{"shared/style.md": "style\n"})
+	if err := os.Symlink(filepath.Join("..", "shared", "style.md"), filepath.Join(repo.Dir, "alpha", "style.md")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := writeGlobalConfig(GlobalConfig{SkillRepository: repo.Dir, Symlinks: symlinkReject}); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, lintCmd(), "alpha")
+	if err == nil || !strings.Contains(output, "alpha/style.md: error: symlink escapes the skill directory") {
+		t.Errorf("lint = %v, output:\n%s", err, output)
+	}
+
+	project := filepath.Join(base, "project")
+	setupTestProject(t, project)
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+	if _, err := runCommand(t, addCmd(), "alpha"); err == nil || !strings.Contains(err.Error(), "escapes the skill directory") {
+		t.Errorf("add = %v, want escape error", err)
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.SkillMap["alpha"]; ok {
+		t.Error("add registered a skill it failed to install")
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func statusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the effective skill configuration for the current directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			layers, err := projectConfigChain(projectRoot)
+			if err != nil {
+				return err
+			}
+			cfg := mergeProjectConfigs(layers)
+			installMode, linkStyle := cfg.installMode("")
+
+			fmt.Fprintf(os.Stdout, "Project: %s\n", projectRoot)
+			fmt.Fprintln(os.Stdout, "Config files:")
+			for _, layer := range layers {
+				fmt.Fprintf(os.Stdout, "  %s\n", filepath.Join(layer.Dir, projectConfigName))
+			}
+			fmt.Fprintf(os.Stdout, "Agents: %s\n", strings.Join(cfg.Agents, ", "))
+			fmt.Fprintf(os.Stdout, "Mode: %s\n", installMode)
+			if installMode == installModeLink {
+				fmt.Fprintf(os.Stdout, "Link style: %s\n", linkStyle)
+			}
+
+			skillNames := sortedSkillNames(cfg)
+			if len(skillNames) == 0 {
+				fmt.Fprintln(os.Stdout, "Skills: none")
+				return nil
+			}
+			fmt.Fprintln(os.Stdout, "Skills:")
+			for _, skillName := range skillNames {
+				origin := skillOrigin(layers, skillName)
+				skillMode, _ := cfg.installMode(skillName)
+				source := ""
+				if name := cfg.sourceName(skillName); name != skillName {
+					source = "source " + name + ", "
+				}
+				fmt.Fprintf(os.Stdout, "  %s (%smode %s, from %s)\n", skillName, source, skillMode, filepath.Join(origin, projectConfigName))
+				for _, agent := range cfg.skillAgents(skillName) {
+					target, err := resolveSkillTarget(projectRoot, skillName, agent, cfg.SkillMap[skillName].Paths)
+					if err != nil {
+						return err
+					}
+					rel, err := filepath.Rel(projectRoot, target)
+					if err != nil {
+						rel = target
+					}
+					fmt.Fprintf(os.Stdout, "    %s -> %s\n", agent, rel)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// skillOrigin returns the directory of the innermost config defining a skill.
+func skillOrigin(layers []projectConfigLayer, skillName string) string {
+	origin := ""
+	for _, layer := range layers {
+		if _, ok := layer.Config.SkillMap[skillName]; ok {
+			origin = layer.Dir
+		}
+	}
+	return origin
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// projectSkill returns the repository source of a project skill, carrying
+// the variables its templates are rendered with.
+func (r skillRepository) projectSkill(cfg ProjectConfig, skillName string) skillSource {
+	src := r.skill(cfg.sourceName(skillName))
+	src.Vars = cfg.skillVars(skillName)
+	return src
+}
+
+// skillRenderer renders the files a skill marks as templates and resolves
+// the agent sections of its markdown files.
+type skillRenderer struct {
+	files ignoreRules
+	vars  map[string]string
+	agent string
+}
+
+// newSkillRenderer prepares the rendering of a source. It returns nil when
+// there is nothing to render: the source carries no agent and either no
+// variables or a skill without templates.
+func newSkillRenderer(src skillSource) (*skillRenderer, error) {
+	r := &skillRenderer{agent: src.Agent}
+	if src.Vars != nil {
+		meta, err := readSkillMetadata(src.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(meta.Templates) > 0 {
+			if r.files, err = compileGlobs(meta.Templates); err != nil {
+				return nil, fmt.Errorf("templates of %s: %w", src.Dir, err)
+			}
+			if r.vars, err = meta.resolveVars(src.Vars); err != nil {
+				return nil, fmt.Errorf("skill %s: %w", filepath.Base(src.Dir), err)
+			}
+		}
+	}
+	if r.vars == nil && r.agent == "" {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// resolveVars applies values set in .skills.yaml over the defaults of the
+// frontmatter and reports declared variables left without a value.
+func (m skillMetadata) resolveVars(values map[string]string) (map[string]string, error) {
+	vars := map[string]string{}
+	var missing []string
+	for name, value := range m.Vars {
+		if value != nil {
+			vars[name] = *value
+		} else if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing template variables %s; set them under vars in %s", strings.Join(missing, ", "), projectConfigName)
+	}
+	for name, value := range values {
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// render returns the rendered content of the file at path, or nil when it
+// is installed as it is. Agent sections are resolved before the template
+// runs, so sections for other agents may use variables this one lacks.
+func (r *skillRenderer) render(rel, path string) ([]byte, error) {
+	isTemplate := false
+	if r.vars != nil {
+		isTemplate, _ = r.files.match(rel, false)
+	}
+	hasSections := r.agent != "" && isMarkdownFile(rel)
+	if !isTemplate && !hasSections {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	if hasSections && containsAgentSections(data) {
+		if content, _, err = resolveAgentSections(data, r.agent); err != nil {
+			return nil, fmt.Errorf("agent sections of %s: %w", path, err)
+		}
+		data = content
+	}
+	if isTemplate {
+		if content, err = executeTemplate(rel, data, r.vars); err != nil {
+			return nil, fmt.Errorf("render template %s: %w", path, err)
+		}
+	}
+	return content, nil
+}
+
+// rendered reports whether installing the source changes any file: the
+// skill declares templates or its markdown has agent sections. Such skills
+// cannot be linked or promoted.
+func (s skillSource) rendered() (bool, error) {
+	meta, err := readSkillMetadata(s.Dir)
+	if err != nil {
+		return false, err
+	}
+	if len(meta.Templates) > 0 {
+		return true, nil
+	}
+	found := false
+	err = s.walk(func(entry skillEntry) error {
+		if found || !entry.Info.Mode().IsRegular() || !isMarkdownFile(entry.Rel) {
+			return nil
+		}
+		data, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return err
+		}
+		found = containsAgentSections(data)
+		return nil
+	})
+	return found, err
+}
+
+// executeTemplate renders data with vars. Referencing a variable that has
+// no value is an error rather than an empty string.
+func executeTemplate(name string, data []byte, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, out.Bytes()...), nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const templateSkill = "---\nname: demo\ntemplates: [\"*.md\"]\nvars:\n  team: platform\n  region:\n---\n{{ .team }}/{{ .region }}\n"
+
+func TestTemplateVariablePrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		skillFile string
+		project   map[string]string
+		skill     map[string]string
+		want      string
+		err       string
+	}{
+		{
+			name:    "frontmatter default",
+			project: map[string]string{"region": "eu"},
+			want:    "platform/eu",
+		},
+		{
+			name:    "project vars over defaults",
+			project: map[string]string{"region": "eu", "team": "web"},
+			want:    "web/eu",
+		},
+		{
+			name:    "skill vars over project vars",
+			project: map[string]string{"region": "eu", "team": "web"},
+			skill:   map[string]string{"region": "us"},
+			want:    "web/us",
+		},
+		{
+			name:    "empty value counts as set",
+			project: map[string]string{"region": ""},
+			want:    "platform/",
+		},
+		{
+			name: "declared variable without a value",
+			err:  "skill demo: missing template variables region; set them under vars in .skills.yaml",
+		},
+		{
+			name:      "undeclared variable",
+			skillFile: "---\ntemplates: [\"*.md\"]\n---\n{{ .team }}\n",
+			err:       `map has no entry for key "team"`,
+		},
+		{
+			name:      "undeclared variable set in the project",
+			skillFile: "---\ntemplates: [\"*.md\"]\n---\n{{ .team }}\n",
+			project:   map[string]string{"team": "web"},
+			want:      "web",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			skillFile := tt.skillFile
+			if skillFile == "" {
+				skillFile = templateSkill
+			}
+			writeTestFiles(t, repoDir, map[string]string{"demo/SKILL.md": skillFile})
+			cfg := ProjectConfig{
+				Agents:   []string{"codex"},
+				Vars:     tt.project,
+				SkillMap: map[string]SkillConfig{"demo": {Vars: tt.skill}},
+			}
+			src := skillRepository{Dir: repoDir, Symlinks: symlinkPreserve}.projectSkill(cfg, "demo")
+			target := filepath.Join(t.TempDir(), "demo")
+			err := copySkillDir(src, target)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("install = %v, want error containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(target, "SKILL.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("rendered %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddVarOverridesProjectVars(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{"demo/SKILL.md": templateSkill})
+	project := filepath.Join(base, "project")
+	writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex]\nvars:\n  region: eu\n  team: web\nskillMap: {}\n"})
+	projectDir = project
+	t.Cleanup(func() { projectDir = "" })
+
+	if _, err := runCommand(t, addCmd(), "demo", "--var", "region=us"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(project, ".codex", "skills", "demo", "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "\nweb/us\n") {
+		t.Errorf("installed SKILL.md:\n%s", data)
+	}
+	cfg, err := readProjectConfigFile(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars := cfg.SkillMap["demo"].Vars; len(vars) != 1 || vars["region"] != "us" {
+		t.Errorf("skill vars = %v, want region=us", vars)
+	}
+
+	if _, err := runCommand(t, addCmd(), "demo", "--var", "not-a-name=x"); err == nil || !strings.HasPrefix(err.Error(), "--var: invalid variable name") {
+		t.Errorf("add --var not-a-name = %v", err)
+	}
+}
+
+func TestLinkModeRefusesRenderedSkills(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"templates", map[string]string{"demo/SKILL.md": templateSkill}},
+		{"agent sections", map[string]string{
+			"demo/SKILL.md": "demo\n",
+			"demo/guide.md": "<!-- gym:if agent=codex -->\ncodex\n<!-- gym:end -->\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, repo := setupTestWorkspace(t)
+			writeTestFiles(t, repo.Dir, tt.files)
+			project := filepath.Join(base, "project")
+			writeTestFiles(t, project, map[string]string{projectConfigName: "agents: [codex]\nvars:\n  region: eu\nskillMap: {}\n"})
+			projectDir = project
+			t.Cleanup(func() { projectDir = "" })
+
+			_, err := runCommand(t, addCmd(), "demo", "--mode", "link")
+			if err == nil || !strings.HasSuffix(err.Error(), "skill demo is rendered on install and cannot be linked; use mode copy") {
+				t.Errorf("add --mode link = %v", err)
+			}
+			if _, err := os.Lstat(filepath.Join(project, ".codex", "skills", "demo")); !os.IsNotExist(err) {
+				t.Errorf("add --mode link left a target: %v", err)
+			}
+			if _, err := runCommand(t, addCmd(), "demo"); err != nil {
+				t.Errorf("add in copy mode: %v", err)
+			}
+		})
+	}
+}
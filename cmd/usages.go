@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// skipScanDirs are never searched for project configs when scanning a
+// directory tree.
+var skipScanDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+func usagesCmd() *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:   "usages <skill-name>",
+		Short: "Show which projects install a skill",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skillName := args[0]
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			projects, err := discoverProjects(globalCfg, dir)
+			if err != nil {
+				return err
+			}
+			usages := make([]skillUsage, 0)
+			for _, project := range projects {
+				found, err := projectSkillUsages(project, globalCfg.repository(), skillName)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "skip %s: %v\n", project, err)
+					continue
+				}
+				usages = append(usages, found...)
+			}
+			if len(usages) == 0 {
+				fmt.Fprintf(os.Stdout, "Skill %s is not installed in any of %d projects\n", skillName, len(projects))
+				return nil
+			}
+			table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "PROJECT\tSKILL\tAGENT\tTARGET\tINSTALLED\tSTATE")
+			for _, usage := range usages {
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", usage.Project, usage.Skill, usage.Agent, usage.Target, shortHash(usage.Installed), usage.State)
+			}
+			return table.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "scan this directory tree for .skills.yaml files instead of registered projects")
+	return cmd
+}
+
+type skillUsage struct {
+	Project   string
+	Skill     string
+	Agent     string
+	Target    string
+	Installed string
+	State     string
+}
+
+// discoverProjects returns the registered projects, or every directory
+// below dir containing a project config when dir is set.
+func discoverProjects(globalCfg GlobalConfig, dir string) ([]string, error) {
+	if dir == "" {
+		projects := make([]string, 0, len(globalCfg.Projects))
+		for _, project := range globalCfg.Projects {
+			exists, err := projectConfigExists(project)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				projects = append(projects, project)
+			}
+		}
+		return projects, nil
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", dir, err)
+	}
+	projects := make([]string, 0)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skipScanDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == projectConfigName {
+			projects = append(projects, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", root, err)
+	}
+	return projects, nil
+}
+
+func usedSkills(projects []string) (map[string]bool, error) {
+	used := map[string]bool{}
+	for _, project := range projects {
+		projectCfg, err := loadProjectConfig(project)
+		if err != nil {
+			return nil, err
+		}
+		for skillName := range projectCfg.SkillMap {
+			used[projectCfg.sourceName(skillName)] = true
+		}
+	}
+	return used, nil
+}
+
+// projectSkillUsages returns where a project installs a repository skill,
+// under its own name or any local name.
+func projectSkillUsages(projectRoot string, repo skillRepository, sourceName string) ([]skillUsage, error) {
+	projectCfg, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+		return nil, err
+	}
+	lock, err := loadProjectLock(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]skillUsage, 0)
+	for _, skillName := range sortedSkillNames(projectCfg) {
+		if projectCfg.sourceName(skillName) != sourceName {
+			continue
+		}
+		found, err := localSkillUsages(projectRoot, repo, projectCfg, lock, skillName)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, found...)
+	}
+	return usages, nil
+}
+
+func localSkillUsages(projectRoot string, repo skillRepository, projectCfg ProjectConfig, lock ProjectLock, skillName string) ([]skillUsage, error) {
+	skillCfg := projectCfg.SkillMap[skillName]
+	installMode, _ := projectCfg.installMode(skillName)
+	skillSrc := repo.projectSkill(projectCfg, skillName)
+	var err error
+	_, srcErr := os.Stat(skillSrc.Dir)
+	agents := projectCfg.skillAgents(skillName)
+	if srcErr == nil {
+		if agents, _, err = installAgents(projectCfg, skillName, skillSrc); err != nil {
+			return nil, err
+		}
+	}
+
+	usages := make([]skillUsage, 0, len(agents))
+	for _, agent := range agents {
+		target, err := resolveSkillTarget(projectRoot, skillName, agent, skillCfg.Paths)
+		if err != nil {
+			return nil, err
+		}
+		usage := skillUsage{
+			Project:   projectRoot,
+			Skill:     skillName,
+			Agent:     agent,
+			Target:    target,
+			Installed: lock.Skills[skillName].Targets[agent],
+		}
+		if srcErr != nil {
+			usage.State = "repo missing"
+		} else {
+			check := targetCheck{skill: skillName, agent: agent, src: skillSrc.forAgent(agent, projectCfg.Filters[agent]), target: target, mode: installMode}
+			if err := check.run(); err != nil {
+				return nil, err
+			}
+			usage.State = usageState(check)
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+func usageState(check targetCheck) string {
+	if check.problem != "" {
+		return check.problem
+	}
+	if !check.drift {
+		return "in sync"
+	}
+	if _, err := os.Lstat(check.target); os.IsNotExist(err) {
+		return "project missing"
+	}
+	return "drifting"
+}
+
+func shortHash(hash string) string {
+	hash = strings.TrimPrefix(hash, "sha256:")
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverProjectsSkipsHiddenAndVendoredDirs(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		".skills.yaml":                  "",
+		"app/.skills.yaml":              "",
+		"app/nested/deep/.skills.yaml":  "",
+		".hidden/.skills.yaml":          "",
+		"app/.git/.skills.yaml":         "",
+		"web/node_modules/.skills.yaml": "",
+		"go/vendor/x/.skills.yaml":      "",
+		"other/skills.yaml":             "",
+	})
+	projects, err := discoverProjects(GlobalConfig{}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{root, filepath.Join(root, "app"), filepath.Join(root, "app", "nested", "deep")}
+	if !slices.Equal(projects, want) {
+		t.Errorf("projects = %v, want %v", projects, want)
+	}
+}
+
+func TestDiscoverProjectsUsesRegisteredProjects(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"kept/.skills.yaml": ""})
+	kept, gone := filepath.Join(root, "kept"), filepath.Join(root, "gone")
+	projects, err := discoverProjects(GlobalConfig{Projects: []string{gone, kept}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(projects, []string{kept}) {
+		t.Errorf("projects = %v, want [%s]", projects, kept)
+	}
+}
+
+func TestListUnused(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	writeTestFiles(t, repo.Dir, map[string]string{
+		"beta/SKILL.md":  "beta\n",
+		"gamma/SKILL.md": "gamma\n",
+	})
+	registered := filepath.Join(base, "registered")
+	setupTestProject(t, registered, "alpha")
+	if err := registerProject(registered); err != nil {
+		t.Fatal(err)
+	}
+	// A local name still marks its source skill as used.
+	scanned := filepath.Join(base, "tree", "scanned")
+	cfg := setupTestProject(t, scanned)
+	cfg.SkillMap["local-beta"] = SkillConfig{Source: "beta"}
+	if err := writeProjectConfig(scanned, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, listCmd(), "--unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "beta\ngamma\n" {
+		t.Errorf("list --unused = %q, want beta and gamma", output)
+	}
+	output, err = runCommand(t, listCmd(), "--unused", "--dir", filepath.Join(base, "tree"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "alpha\ngamma\n" {
+		t.Errorf("list --unused --dir = %q, want alpha and gamma", output)
+	}
+
+	cfg.SkillMap["gamma"] = SkillConfig{}
+	cfg.SkillMap["alpha"] = SkillConfig{}
+	if err := writeProjectConfig(scanned, cfg); err != nil {
+		t.Fatal(err)
+	}
+	output, err = runCommand(t, listCmd(), "--unused", "--dir", filepath.Join(base, "tree"))
+	if err != nil || !strings.HasPrefix(output, "No unused skills found") {
+		t.Errorf("list --unused with every skill used = %q, %v", output, err)
+	}
+}
+
+func TestProjectSkillUsages(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	project := filepath.Join(base, "project")
+	cfg := setupTestProject(t, project, "alpha")
+	cfg.SkillMap["local-alpha"] = SkillConfig{Source: "alpha"}
+	if err := writeProjectConfig(project, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncSkills(io.Discard, project, repo, cfg, []string{"alpha"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	usages, err := projectSkillUsages(project, repo, "alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := map[string]string{}
+	for _, usage := range usages {
+		states[usage.Skill] = usage.State
+	}
+	if len(usages) != 2 || states["alpha"] != "in sync" || states["local-alpha"] != "project missing" {
+		t.Errorf("usages = %+v", usages)
+	}
+}
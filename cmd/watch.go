@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// changeNotifier wakes the watch loop when something below the watched
+// directories may have changed.
+type changeNotifier interface {
+	Events() <-chan struct{}
+	Watch(dirs []string) error
+}
+
+func watchCmd() *cobra.Command {
+	var interval time.Duration
+	var debounce time.Duration
+	var poll bool
+	var jobs int
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Sync skills into the project whenever the repository changes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return errors.New("--interval must be positive")
+			}
+			projectRoot, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+
+			var notifier changeNotifier
+			if !poll {
+				notifier, err = newChangeNotifier()
+				if err != nil {
+					fmt.Fprintf(os.Stdout, "Falling back to polling every %s: %v\n", interval, err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			w := &skillWatcher{
+				out:         os.Stdout,
+				projectRoot: projectRoot,
+				repo:        globalCfg.repository(),
+				notifier:    notifier,
+				interval:    interval,
+				debounce:    debounce,
+				jobs:        jobs,
+			}
+			return w.run(ctx)
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "polling interval when file notifications are unavailable")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "quiet period before syncing a burst of changes")
+	cmd.Flags().BoolVar(&poll, "poll", false, "poll for changes instead of using file notifications")
+	addJobsFlag(cmd, &jobs)
+	return cmd
+}
+
+type skillWatcher struct {
+	out         io.Writer
+	projectRoot string
+	repo        skillRepository
+	notifier    changeNotifier
+	interval    time.Duration
+	debounce    time.Duration
+	jobs        int
+
+	config       string
+	fingerprints map[string]string
+	// retry is set while some changed skills could not be synced; they
+	// are retried after interval even without new file events.
+	retry bool
+	// skipped remembers targets reported as locally edited, so retries
+	// do not repeat the message.
+	skipped map[string]bool
+}
+
+func (w *skillWatcher) run(ctx context.Context) error {
+	projectCfg, err := loadProjectConfig(w.projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+		return err
+	}
+	w.config, err = pathFingerprint(filepath.Join(w.projectRoot, projectConfigName))
+	if err != nil {
+		return err
+	}
+	w.fingerprints, err = w.skillFingerprints(projectCfg)
+	if err != nil {
+		return err
+	}
+	if err := w.watchDirs(projectCfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "Watching %s for %d skills (Ctrl+C to stop)\n", w.repo.Dir, len(projectCfg.SkillMap))
+
+	var ticks <-chan time.Time
+	var events <-chan struct{}
+	if w.notifier != nil {
+		events = w.notifier.Events()
+	} else {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		var retry <-chan time.Time
+		if w.retry && ticks == nil {
+			retry = time.After(w.interval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-events:
+			if !w.settle(ctx, events) {
+				return nil
+			}
+		case <-ticks:
+		case <-retry:
+		}
+		projectCfg, err = w.rescan(projectCfg)
+		if err != nil {
+			fmt.Fprintf(w.out, "%s error: %v\n", watchTimestamp(), err)
+		}
+	}
+}
+
+// settle waits until no events arrived for the debounce period.
+func (w *skillWatcher) settle(ctx context.Context, events <-chan struct{}) bool {
+	timer := time.NewTimer(w.debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-events:
+			timer.Reset(w.debounce)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// rescan compares fingerprints with the previous scan and syncs the skills
+// that changed. A changed .skills.yaml reloads the config and syncs every
+// skill, so newly registered skills and agents are picked up.
+func (w *skillWatcher) rescan(projectCfg ProjectConfig) (ProjectConfig, error) {
+	configPrint, err := pathFingerprint(filepath.Join(w.projectRoot, projectConfigName))
+	if err != nil {
+		return projectCfg, err
+	}
+	configChanged := configPrint != w.config
+	if configChanged {
+		reloaded, err := loadProjectConfig(w.projectRoot)
+		if err != nil {
+			return projectCfg, err
+		}
+		if err := ensureSupportedAgents(reloaded.Agents); err != nil {
+			return projectCfg, err
+		}
+		w.config = configPrint
+		projectCfg = reloaded
+		fmt.Fprintf(w.out, "%s %s changed\n", watchTimestamp(), projectConfigName)
+	}
+
+	fingerprints, err := w.skillFingerprints(projectCfg)
+	if err != nil {
+		return projectCfg, err
+	}
+	changed := make([]string, 0)
+	for _, skillName := range sortedSkillNames(projectCfg) {
+		if configChanged || fingerprints[skillName] != w.fingerprints[skillName] {
+			changed = append(changed, skillName)
+		}
+	}
+	if err := w.watchDirs(projectCfg); err != nil {
+		return projectCfg, err
+	}
+	done, syncErr := w.syncChanged(projectCfg, changed)
+	if len(changed) > 0 {
+		if err := saveHashCache(); err != nil && syncErr == nil {
+			syncErr = err
+		}
+	}
+	// Skills that failed or were skipped keep an empty fingerprint, so the
+	// next scan sees them as changed and retries them. A skill missing
+	// from the repository fingerprints as empty too and is only retried
+	// once it appears.
+	for _, skillName := range changed {
+		if !slices.Contains(done, skillName) {
+			fingerprints[skillName] = ""
+		}
+	}
+	w.fingerprints = fingerprints
+	w.retry = len(done) < len(changed)
+	return projectCfg, syncErr
+}
+
+// syncChanged syncs the given skills and returns those that were synced
+// for every agent.
+func (w *skillWatcher) syncChanged(projectCfg ProjectConfig, skillNames []string) ([]string, error) {
+	if len(skillNames) == 0 {
+		return nil, nil
+	}
+	// The lock is only held while syncing, so other gym commands can run
+	// while the watcher is idle.
+	stateLock, err := lockProject(w.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer stateLock.release()
+	available := make([]string, 0, len(skillNames))
+	for _, skillName := range skillNames {
+		if _, err := os.Stat(filepath.Join(w.repo.Dir, projectCfg.sourceName(skillName))); err != nil {
+			fmt.Fprintf(w.out, "%s skipped %s: not found in repository\n", watchTimestamp(), skillName)
+			continue
+		}
+		available = append(available, skillName)
+	}
+	tasks, err := planInstallTasks(w.out, w.projectRoot, w.repo, projectCfg, available)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := loadProjectLock(w.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]installTask, 0, len(tasks))
+	synced := map[string][]string{}
+	incomplete := map[string]bool{}
+	for _, task := range tasks {
+		edited, upToDate, err := targetEdited(lock, projectCfg, task)
+		if err != nil {
+			return nil, err
+		}
+		key := task.skill + "/" + task.agent
+		if edited {
+			incomplete[task.skill] = true
+			if !w.skipped[key] {
+				fmt.Fprintf(w.out, "%s skipped %s for %s: %s has local edits (run gym sync to overwrite)\n", watchTimestamp(), task.skill, task.agent, task.target)
+			}
+			if w.skipped == nil {
+				w.skipped = map[string]bool{}
+			}
+			w.skipped[key] = true
+			continue
+		}
+		delete(w.skipped, key)
+		kept = append(kept, task)
+		if !upToDate {
+			synced[task.skill] = append(synced[task.skill], task.agent)
+		}
+	}
+	if err := runInstallTasks(io.Discard, w.projectRoot, projectCfg, kept, w.jobs); err != nil {
+		return nil, err
+	}
+	done := make([]string, 0, len(available))
+	for _, skillName := range available {
+		if agents := synced[skillName]; len(agents) > 0 {
+			fmt.Fprintf(w.out, "%s synced %s (%s)\n", watchTimestamp(), skillName, strings.Join(agents, ", "))
+		}
+		if !incomplete[skillName] {
+			done = append(done, skillName)
+		}
+	}
+	return done, nil
+}
+
+// targetEdited reports whether an installed target was changed locally
+// since gym last wrote it, and whether it already matches the repository.
+// Missing targets and targets that already match the repository are never
+// considered edited.
+func targetEdited(lock ProjectLock, projectCfg ProjectConfig, task installTask) (edited, upToDate bool, err error) {
+	if mode, _ := projectCfg.installMode(task.skill); mode == installModeLink {
+		return false, false, nil
+	}
+	if _, err := os.Lstat(task.target); err != nil {
+		if os.IsNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	match, err := dirsEqual(task.src, task.target)
+	if err != nil || match {
+		return false, match, err
+	}
+	recorded, ok := lock.Skills[task.skill].Targets[task.agent]
+	if !ok {
+		return true, false, nil
+	}
+	current, err := hashDir(task.target)
+	if err != nil {
+		return false, false, err
+	}
+	return current != recorded, false, nil
+}
+
+func (w *skillWatcher) watchDirs(projectCfg ProjectConfig) error {
+	if w.notifier == nil {
+		return nil
+	}
+	dirs := []string{w.projectRoot}
+	for _, skillName := range sortedSkillNames(projectCfg) {
+		skillSrc := w.repo.skill(projectCfg.sourceName(skillName))
+		if _, err := os.Stat(skillSrc.Dir); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		dirs = append(dirs, skillSrc.Dir)
+		// Walking the source also watches the targets of dereferenced links.
+		if err := skillSrc.walk(func(entry skillEntry) error {
+			if entry.Info.IsDir() {
+				dirs = append(dirs, entry.Path)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	// The repository root catches skills that are created after startup.
+	dirs = append(dirs, w.repo.Dir)
+	slices.Sort(dirs)
+	return w.notifier.Watch(slices.Compact(dirs))
+}
+
+func (w *skillWatcher) skillFingerprints(projectCfg ProjectConfig) (map[string]string, error) {
+	fingerprints := map[string]string{}
+	for skillName := range projectCfg.SkillMap {
+		print, err := sourceFingerprint(w.repo.skill(projectCfg.sourceName(skillName)))
+		if err != nil {
+			return nil, err
+		}
+		fingerprints[skillName] = print
+	}
+	return fingerprints, nil
+}
+
+// pathFingerprint summarizes the names, sizes, modes and mtimes below path
+// without reading file contents. A missing path has an empty fingerprint.
+func pathFingerprint(path string) (string, error) {
+	digest := fnv.New64a()
+	err := filepath.WalkDir(path, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(digest, "%s %d %o %d\n", walkPath, info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%x", digest.Sum64()), nil
+}
+
+// sourceFingerprint is pathFingerprint for a skill source, following the
+// links its symlink policy dereferences.
+func sourceFingerprint(src skillSource) (string, error) {
+	digest := fnv.New64a()
+	for _, path := range src.ignoreFiles() {
+		print, err := pathFingerprint(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(digest, "%s %s\n", path, print)
+	}
+	err := src.walk(func(entry skillEntry) error {
+		info := entry.Info
+		fmt.Fprintf(digest, "%s %d %o %d\n", entry.Rel, info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%x", digest.Sum64()), nil
+}
+
+func watchTimestamp() string {
+	return time.Now().Format("15:04:05")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_DELETE_SELF |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyNotifier signals changes using the kernel inotify API. Each
+// directory is watched individually, so Watch is called again after every
+// rescan to pick up new directories.
+type inotifyNotifier struct {
+	fd      int
+	events  chan struct{}
+	mu      sync.Mutex
+	watched map[string]bool
+}
+
+func newChangeNotifier() (changeNotifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	n := &inotifyNotifier{
+		fd:      fd,
+		events:  make(chan struct{}, 1),
+		watched: map[string]bool{},
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotifyNotifier) Events() <-chan struct{} {
+	return n.events
+}
+
+func (n *inotifyNotifier) Watch(dirs []string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, dir := range dirs {
+		if n.watched[dir] {
+			continue
+		}
+		if _, err := syscall.InotifyAddWatch(n.fd, dir, inotifyMask); err != nil {
+			if err == syscall.ENOENT {
+				continue
+			}
+			return fmt.Errorf("watch %s: %w", dir, err)
+		}
+		n.watched[dir] = true
+	}
+	return nil
+}
+
+func (n *inotifyNotifier) read() {
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := syscall.Read(n.fd, buf)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			return
+		}
+		if count <= 0 {
+			return
+		}
+		n.forgetRemoved(buf[:count])
+		select {
+		case n.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// forgetRemoved drops bookkeeping for watches the kernel removed, so a
+// directory recreated under the same path is watched again.
+func (n *inotifyNotifier) forgetRemoved(buf []byte) {
+	removed := false
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		if event.Mask&syscall.IN_IGNORED != 0 {
+			removed = true
+		}
+		offset += syscall.SizeofInotifyEvent + int(event.Len)
+	}
+	if !removed {
+		return
+	}
+	n.mu.Lock()
+	n.watched = map[string]bool{}
+	n.mu.Unlock()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cmd
+
+import "errors"
+
+func newChangeNotifier() (changeNotifier, error) {
+	return nil, errors.New("file notifications are not supported on this platform")
+}
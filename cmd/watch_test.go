@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchRetriesSkillsAfterFailedSync(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	repo := skillRepository{Dir: filepath.Join(base, "repo"), Symlinks: symlinkPreserve}
+	projectRoot := filepath.Join(base, "project")
+	skillFile := filepath.Join(repo.Dir, "alpha", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(skillFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skillFile, []byte("v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := ProjectConfig{Agents: []string{"codex"}, SkillMap: map[string]SkillConfig{"alpha": {}}}
+	if err := writeProjectConfig(projectRoot, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := syncSkills(&bytes.Buffer{}, projectRoot, repo, cfg, []string{"alpha"}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	w := &skillWatcher{out: &out, projectRoot: projectRoot, repo: repo, interval: time.Millisecond, jobs: 1}
+	w.config, err = pathFingerprint(filepath.Join(projectRoot, projectConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.fingerprints, err = w.skillFingerprints(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later mtime makes sure the fingerprint changes on coarse clocks.
+	if err := os.WriteFile(skillFile, []byte("v2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(skillFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	held, err := lockProject(projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.rescan(cfg); err == nil {
+		t.Fatal("rescan succeeded while the project lock was held")
+	}
+	if !w.retry {
+		t.Error("failed sync did not schedule a retry")
+	}
+	held.release()
+
+	if _, err := w.rescan(cfg); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	target := filepath.Join(projectRoot, ".codex", "skills", "alpha", "SKILL.md")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "v2\n" {
+		t.Errorf("target holds %q after retry, want %q", data, "v2\n")
+	}
+	if w.retry {
+		t.Error("retry still pending after a successful sync")
+	}
+	if !strings.Contains(out.String(), "synced alpha (codex)") {
+		t.Errorf("output %q does not report the sync", out.String())
+	}
+}
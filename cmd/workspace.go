@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	workspaceOK       = "ok"
+	workspaceDrifting = "drifting"
+	workspaceOutdated = "outdated"
+	workspaceMissing  = "missing"
+	workspaceError    = "error"
+)
+
+// registerProject adds a project root to the workspace registry in the
+// global config. Without a config file, as when GYM_SKILL_REPOSITORY
+// configures gym, there is no registry and nothing is recorded.
+func registerProject(projectRoot string) error {
+	configLock, err := lockGlobalConfig()
+	if err != nil {
+		return err
+	}
+	defer configLock.release()
+	cfg, err := readGlobalConfigFile()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return fmt.Errorf("resolve project root: %w", err)
+	}
+	if slices.Contains(cfg.Projects, root) {
+		return nil
+	}
+	cfg.Projects = append(cfg.Projects, root)
+	slices.Sort(cfg.Projects)
+	return writeGlobalConfig(cfg)
+}
+
+func unregisterProject(projectRoot string) (bool, error) {
+	configLock, err := lockGlobalConfig()
+	if err != nil {
+		return false, err
+	}
+	defer configLock.release()
+	cfg, err := readGlobalConfigFile()
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return false, fmt.Errorf("resolve project root: %w", err)
+	}
+	idx := slices.Index(cfg.Projects, root)
+	if idx < 0 {
+		return false, nil
+	}
+	cfg.Projects = slices.Delete(cfg.Projects, idx, idx+1)
+	return true, writeGlobalConfig(cfg)
+}
+
+func workspaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Work with all projects registered in the global config",
+	}
+	cmd.AddCommand(workspaceListCmd())
+	cmd.AddCommand(workspaceAddCmd())
+	cmd.AddCommand(workspaceRemoveCmd())
+	cmd.AddCommand(workspaceSyncCmd())
+	cmd.AddCommand(workspaceDriftCmd())
+	cmd.AddCommand(workspaceCheckCmd())
+	return cmd
+}
+
+func workspaceListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			if len(globalCfg.Projects) == 0 {
+				fmt.Fprintln(os.Stdout, "No projects registered")
+				return nil
+			}
+			for _, project := range globalCfg.Projects {
+				exists, err := projectConfigExists(project)
+				if err != nil {
+					return err
+				}
+				if exists {
+					fmt.Fprintln(os.Stdout, project)
+				} else {
+					fmt.Fprintf(os.Stdout, "%s (missing)\n", project)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func workspaceAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add [dir]",
+		Short: "Register a project (defaults to the current project)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectRoot, err := workspaceArgDir(args)
+			if err != nil {
+				return err
+			}
+			exists, err := projectConfigExists(projectRoot)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("%s has no %s; run gym init there first", projectRoot, projectConfigName)
+			}
+			if err := registerProject(projectRoot); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Registered %s\n", projectRoot)
+			return nil
+		},
+	}
+}
+
+func workspaceRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove [dir]",
+		Short: "Unregister a project (defaults to the current project)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectRoot, err := workspaceArgDir(args)
+			if err != nil {
+				return err
+			}
+			removed, err := unregisterProject(projectRoot)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("%s is not registered", projectRoot)
+			}
+			fmt.Fprintf(os.Stdout, "Unregistered %s\n", projectRoot)
+			return nil
+		},
+	}
+}
+
+func workspaceArgDir(args []string) (string, error) {
+	if len(args) == 0 {
+		return findProjectRoot()
+	}
+	abs, err := filepath.Abs(args[0])
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	return abs, nil
+}
+
+func workspaceSyncCmd() *cobra.Command {
+	var jobs int
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize skills in every registered project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			return syncEachProject(globalCfg.Projects, globalCfg.repository(), jobs)
+		},
+	}
+	addJobsFlag(cmd, &jobs)
+	return cmd
+}
+
+// syncEachProject syncs several projects in turn, reporting failures per
+// project instead of stopping at the first one.
+func syncEachProject(projects []string, repo skillRepository, jobs int) error {
+	failed := 0
+	for _, project := range projects {
+		fmt.Fprintf(os.Stdout, "== %s\n", project)
+		exists, err := projectConfigExists(project)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			fmt.Fprintf(os.Stdout, "Skipped: no %s\n", projectConfigName)
+			continue
+		}
+		if err := syncProject(project, repo, jobs); err != nil {
+			fmt.Fprintf(os.Stdout, "error: %v\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("sync failed for %d of %d projects", failed, len(projects))
+	}
+	return nil
+}
+
+// syncProject syncs every registered skill of the project at projectRoot.
+func syncProject(projectRoot string, repo skillRepository, jobs int) error {
+	stateLock, err := lockProject(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer stateLock.release()
+	projectCfg, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := ensureSupportedAgents(projectCfg.Agents); err != nil {
+		return err
+	}
+	if len(projectCfg.SkillMap) == 0 {
+		fmt.Fprintln(os.Stdout, "No skills registered in .skills.yaml")
+		return nil
+	}
+	return syncSkills(os.Stdout, projectRoot, repo, projectCfg, sortedSkillNames(projectCfg), jobs)
+}
+
+func workspaceDriftCmd() *cobra.Command {
+	var jobs int
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "List drifting skills in every registered project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			found := false
+			for _, project := range globalCfg.Projects {
+				exists, err := projectConfigExists(project)
+				if err != nil {
+					return err
+				}
+				if !exists {
+					fmt.Fprintf(os.Stdout, "%s: missing %s\n", project, projectConfigName)
+					found = true
+					continue
+				}
+				drifted, err := projectDriftSkills(project, globalCfg.repository(), jobs)
+				if err != nil {
+					fmt.Fprintf(os.Stdout, "%s: error: %v\n", project, err)
+					found = true
+					continue
+				}
+				for _, item := range drifted {
+					found = true
+					fmt.Fprintf(
+						os.Stdout,
+						"%s: %s: repo=%s project=%s status=%s\n",
+						project,
+						item.Label,
+						formatModTime(item.RepoTime),
+						formatModTime(item.ProjectTime),
+						item.Status,
+					)
+				}
+			}
+			if !found {
+				fmt.Fprintln(os.Stdout, "No drifting skills found")
+			}
+			return nil
+		},
+	}
+	addJobsFlag(cmd, &jobs)
+	return cmd
+}
+
+type workspaceStatus struct {
+	Project  string
+	Status   string
+	Skills   int
+	Drifting int
+	Outdated int
+	Err      error
+}
+
+func workspaceCheckCmd() *cobra.Command {
+	var jobs int
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Summarize which registered projects are drifting or outdated",
+		Long: "Summarize which registered projects are drifting or outdated.\n\n" +
+			"A skill is outdated when the repository changed since it was last synced,\n" +
+			"and drifting when its project copies differ from the repository for any\n" +
+			"other reason, such as local edits. Exits with an error if any project is\n" +
+			"not ok.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateJobs(jobs); err != nil {
+				return err
+			}
+			globalCfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			if len(globalCfg.Projects) == 0 {
+				fmt.Fprintln(os.Stdout, "No projects registered")
+				return nil
+			}
+			table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "PROJECT\tSTATUS\tSKILLS\tDRIFTING\tOUTDATED")
+			statuses := make([]workspaceStatus, 0, len(globalCfg.Projects))
+			unhealthy := 0
+			for _, project := range globalCfg.Projects {
+				status := checkProject(project, globalCfg.repository(), jobs)
+				if status.Status != workspaceOK {
+					unhealthy++
+				}
+				statuses = append(statuses, status)
+				fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\n", status.Project, status.Status, status.Skills, status.Drifting, status.Outdated)
+			}
+			if err := table.Flush(); err != nil {
+				return err
+			}
+			for _, status := range statuses {
+				if status.Err != nil {
+					fmt.Fprintf(os.Stdout, "%s: %v\n", status.Project, status.Err)
+				}
+			}
+			if unhealthy > 0 {
+				return fmt.Errorf("%d of %d projects need attention", unhealthy, len(globalCfg.Projects))
+			}
+			return nil
+		},
+	}
+	addJobsFlag(cmd, &jobs)
+	return cmd
+}
+
+func checkProject(projectRoot string, repo skillRepository, jobs int) workspaceStatus {
+	status := workspaceStatus{Project: projectRoot}
+	exists, err := projectConfigExists(projectRoot)
+	if err != nil {
+		status.Status, status.Err = workspaceError, err
+		return status
+	}
+	if !exists {
+		status.Status = workspaceMissing
+		return status
+	}
+	projectCfg, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		status.Status, status.Err = workspaceError, err
+		return status
+	}
+	status.Skills = len(projectCfg.SkillMap)
+
+	drifted, err := projectDriftSkills(projectRoot, repo, jobs)
+	if err != nil {
+		status.Status, status.Err = workspaceError, err
+		return status
+	}
+	outdated, err := outdatedSkills(projectRoot, repo, projectCfg)
+	if err != nil {
+		status.Status, status.Err = workspaceError, err
+		return status
+	}
+	status.Outdated = len(outdated)
+	for _, item := range drifted {
+		if !slices.Contains(outdated, item.Skill) {
+			status.Drifting++
+		}
+	}
+
+	switch {
+	case status.Outdated > 0:
+		status.Status = workspaceOutdated
+	case status.Drifting > 0:
+		status.Status = workspaceDrifting
+	default:
+		status.Status = workspaceOK
+	}
+	return status
+}
+
+// outdatedSkills lists the skills whose repository content changed since
+// they were last installed, or that were never installed.
+func outdatedSkills(projectRoot string, repo skillRepository, projectCfg ProjectConfig) ([]string, error) {
+	lock, err := loadProjectLock(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	outdated := make([]string, 0)
+	for _, skillName := range sortedSkillNames(projectCfg) {
+		entry, ok := lock.Skills[skillName]
+		if !ok {
+			outdated = append(outdated, skillName)
+			continue
+		}
+		current, err := newDirHasher().hashSource(repo.projectSkill(projectCfg, skillName))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("skill %q not found in repository: %w", projectCfg.sourceName(skillName), err)
+			}
+			return nil, err
+		}
+		if current != entry.Source {
+			outdated = append(outdated, skillName)
+		}
+	}
+	return outdated, nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setupTestWorkspace creates a repository with one skill and a global
+// config pointing at it.
+func setupTestWorkspace(t *testing.T) (string, skillRepository) {
+	t.Helper()
+	base := setupTestHome(t)
+	repo := skillRepository{Dir: filepath.Join(base, "repo"), Symlinks: symlinkPreserve}
+	writeTestFiles(t, repo.Dir, map[string]string{"alpha/SKILL.md": "alpha\n"})
+	if err := writeGlobalConfig(GlobalConfig{SkillRepository: repo.Dir}); err != nil {
+		t.Fatal(err)
+	}
+	return base, repo
+}
+
+// setupTestProject writes a project config installing skills for codex.
+func setupTestProject(t *testing.T, projectRoot string, skillNames ...string) ProjectConfig {
+	t.Helper()
+	cfg := ProjectConfig{Agents: []string{"codex"}, SkillMap: map[string]SkillConfig{}}
+	for _, skillName := range skillNames {
+		cfg.SkillMap[skillName] = SkillConfig{}
+	}
+	if err := os.MkdirAll(projectRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeProjectConfig(projectRoot, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRegisterProject(t *testing.T) {
+	base, _ := setupTestWorkspace(t)
+	one, two := filepath.Join(base, "one"), filepath.Join(base, "two")
+	for _, project := range []string{two, one, two} {
+		if err := registerProject(project); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg, err := readGlobalConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Projects, []string{one, two}) {
+		t.Errorf("projects = %v, want [%s %s]", cfg.Projects, one, two)
+	}
+
+	removed, err := unregisterProject(two)
+	if err != nil || !removed {
+		t.Fatalf("unregister = %v, %v", removed, err)
+	}
+	if removed, err = unregisterProject(two); err != nil || removed {
+		t.Errorf("second unregister = %v, %v; want false", removed, err)
+	}
+	if cfg, _ = readGlobalConfigFile(); !slices.Equal(cfg.Projects, []string{one}) {
+		t.Errorf("projects after unregister = %v", cfg.Projects)
+	}
+}
+
+func TestRegisterProjectWithoutConfigFile(t *testing.T) {
+	base := setupTestHome(t)
+	t.Setenv(envSkillRepository, filepath.Join(base, "repo"))
+	if err := registerProject(filepath.Join(base, "project")); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := globalConfigExists(); err != nil || exists {
+		t.Errorf("registering created a global config: %v, %v", exists, err)
+	}
+	if removed, err := unregisterProject(filepath.Join(base, "project")); err != nil || removed {
+		t.Errorf("unregister = %v, %v; want false", removed, err)
+	}
+}
+
+func TestWorkspaceCheck(t *testing.T) {
+	base, repo := setupTestWorkspace(t)
+	synced := filepath.Join(base, "synced")
+	cfg := setupTestProject(t, synced, "alpha")
+	if err := syncSkills(io.Discard, synced, repo, cfg, []string{"alpha"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := registerProject(synced); err != nil {
+		t.Fatal(err)
+	}
+	output, err := runCommand(t, workspaceCmd(), "check")
+	if err != nil {
+		t.Fatalf("check of a synced project failed: %v\n%s", err, output)
+	}
+	if status := checkProject(synced, repo, 1); status.Status != workspaceOK {
+		t.Errorf("synced project status %q", status.Status)
+	}
+
+	writeTestFiles(t, synced, map[string]string{".codex/skills/alpha/SKILL.md": "edited\n"})
+	if status := checkProject(synced, repo, 1); status.Status != workspaceDrifting || status.Drifting != 1 {
+		t.Errorf("edited project status %+v", status)
+	}
+	writeTestFiles(t, repo.Dir, map[string]string{"alpha/SKILL.md": "changed\n"})
+	if status := checkProject(synced, repo, 1); status.Status != workspaceOutdated || status.Outdated != 1 {
+		t.Errorf("outdated project status %+v", status)
+	}
+
+	if err := registerProject(filepath.Join(base, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	if status := checkProject(filepath.Join(base, "gone"), repo, 1); status.Status != workspaceMissing {
+		t.Errorf("missing project status %q", status.Status)
+	}
+	output, err = runCommand(t, workspaceCmd(), "check")
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 projects need attention") {
+		t.Errorf("check err = %v, want 2 of 2 projects\n%s", err, output)
+	}
+}
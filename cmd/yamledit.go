@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalPreserving marshals value for writing to path. When path already
+// holds a YAML document, the new content is merged into its node tree so
+// comments, key order, anchors and indentation of untouched parts survive
+// and only the changed entries show up in a diff.
+func marshalPreserving(path string, value interface{}) ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(value); err != nil {
+		return nil, err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var doc yaml.Node
+	if len(bytes.TrimSpace(existing)) == 0 || yaml.Unmarshal(existing, &doc) != nil ||
+		doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return yaml.Marshal(value)
+	}
+	doc.Content[0] = mergeYAMLNode(doc.Content[0], &updated, reflect.TypeOf(value))
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(yamlIndent(existing))
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	// Dropping an entry that held an anchor leaves its aliases dangling;
+	// such documents are written from scratch instead.
+	var check interface{}
+	if err := yaml.Unmarshal(out.Bytes(), &check); err != nil {
+		return yaml.Marshal(value)
+	}
+	return out.Bytes(), nil
+}
+
+// mergeYAMLNode returns a node with the content of updated that reuses as
+// much of old as possible. typ is the Go type the node was marshalled from.
+func mergeYAMLNode(old, updated *yaml.Node, typ reflect.Type) *yaml.Node {
+	if yamlEquivalent(old, updated) {
+		return old
+	}
+	if old.Kind != updated.Kind || old.Kind == yaml.AliasNode {
+		updated.HeadComment = old.HeadComment
+		updated.LineComment = old.LineComment
+		updated.FootComment = old.FootComment
+		return updated
+	}
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch old.Kind {
+	case yaml.MappingNode:
+		mergeYAMLMapping(old, updated, typ)
+	case yaml.SequenceNode:
+		mergeYAMLSequence(old, updated)
+	default:
+		old.Value = updated.Value
+		old.Tag = updated.Tag
+		if updated.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle) != 0 {
+			old.Style = updated.Style
+		}
+	}
+	return old
+}
+
+// mergeYAMLMapping updates old in place and keeps existing keys in their
+// position. For structs, keys that are not fields are left alone and new
+// fields are inserted in declaration order. For maps, missing keys are
+// removed and new keys are inserted before the first existing key that
+// sorts after them, so sorted maps stay sorted.
+func mergeYAMLMapping(old, updated *yaml.Node, typ reflect.Type) {
+	fields := yamlFields(typ)
+	values := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		values[updated.Content[i].Value] = updated.Content[i+1]
+	}
+	content := make([]*yaml.Node, 0, len(updated.Content))
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key := old.Content[i]
+		value, ok := values[key.Value]
+		if !ok {
+			if _, known := fields[key.Value]; fields != nil && !known {
+				content = append(content, key, old.Content[i+1])
+			}
+			continue
+		}
+		seen[key.Value] = true
+		content = append(content, key, mergeYAMLNode(old.Content[i+1], value, yamlChildType(typ, fields, key.Value)))
+	}
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key := updated.Content[i]
+		if seen[key.Value] {
+			continue
+		}
+		at := len(content)
+		if fields != nil {
+			for j := len(content) - 2; j >= 0; j -= 2 {
+				if field, known := fields[content[j].Value]; known && field.Index[0] < fields[key.Value].Index[0] {
+					at = j + 2
+					break
+				}
+				at = j
+			}
+		} else {
+			for j := 0; j < len(content); j += 2 {
+				if strings.Compare(content[j].Value, key.Value) > 0 {
+					at = j
+					break
+				}
+			}
+		}
+		// Head comments stay with their keys. A file comment separated from
+		// the first key by a blank line belongs to the document node and
+		// stays on top anyway.
+		content = append(content[:at], append([]*yaml.Node{key, updated.Content[i+1]}, content[at:]...)...)
+	}
+	// An empty flow mapping such as `skillMap: {}` switches to block style
+	// once it gets entries.
+	if len(old.Content) == 0 && old.Style&yaml.FlowStyle != 0 {
+		old.Style &^= yaml.FlowStyle
+	}
+	old.Content = content
+}
+
+// yamlFields maps the YAML keys of a struct type to its fields. Types that
+// marshal themselves are treated like maps and return nil.
+func yamlFields(typ reflect.Type) map[string]reflect.StructField {
+	if typ == nil || typ.Kind() != reflect.Struct || typ.Implements(reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()) {
+		return nil
+	}
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func yamlChildType(typ reflect.Type, fields map[string]reflect.StructField, key string) reflect.Type {
+	if fields != nil {
+		return fields[key].Type
+	}
+	if typ != nil && typ.Kind() == reflect.Map {
+		return typ.Elem()
+	}
+	return nil
+}
+
+// mergeYAMLSequence keeps the old item nodes of values that are still
+// present, along with their comments.
+func mergeYAMLSequence(old, updated *yaml.Node) {
+	used := make([]bool, len(old.Content))
+	content := make([]*yaml.Node, 0, len(updated.Content))
+	for _, item := range updated.Content {
+		reused := item
+		for i, oldItem := range old.Content {
+			if !used[i] && yamlEquivalent(oldItem, item) {
+				used[i] = true
+				reused = oldItem
+				break
+			}
+		}
+		content = append(content, reused)
+	}
+	if len(old.Content) == 0 && old.Style&yaml.FlowStyle != 0 {
+		old.Style &^= yaml.FlowStyle
+	}
+	old.Content = content
+}
+
+// yamlEquivalent compares the decoded values of two nodes, so aliases and
+// formatting differences do not count.
+func yamlEquivalent(a, b *yaml.Node) bool {
+	var left, right interface{}
+	if a.Decode(&left) != nil || b.Decode(&right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalizeYAMLValue(left), normalizeYAMLValue(right))
+}
+
+// normalizeYAMLValue treats a null mapping value like an empty mapping,
+// since a skill entry written as `name:` and `name: {}` mean the same.
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{}
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeYAMLValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeYAMLValue(item)
+		}
+		return out
+	}
+	return value
+}
+
+// yamlIndent guesses the indentation of an existing document from its
+// least indented nested line, defaulting to the yaml.Marshal width.
+func yamlIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		width := len(line) - len(trimmed)
+		if width > 0 && (indent == 0 || width < indent) {
+			indent = width
+		}
+	}
+	if indent < 2 || indent > 8 {
+		return 4
+	}
+	return indent
+}
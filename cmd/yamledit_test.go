@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshalPreservingKeepsHeadComments(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		add  string
+		want string
+	}{
+		{
+			name: "nested first key",
+			file: "agents:\n  - codex\nskillMap:\n  # the zeta one\n  zeta: {}\n",
+			add:  "beta",
+			want: "version: 1\nagents:\n  - codex\nskillMap:\n  beta: {}\n  # the zeta one\n  zeta: {}\n",
+		},
+		{
+			name: "root first key",
+			file: "# about agents\nagents:\n  - codex\nskillMap:\n  zeta: {}\n",
+			add:  "beta",
+			want: "version: 1\n# about agents\nagents:\n  - codex\nskillMap:\n  beta: {}\n  zeta: {}\n",
+		},
+		{
+			name: "file comment",
+			file: "# project skills\n\nagents:\n  - codex\nskillMap:\n  zeta: {}\n",
+			add:  "beta",
+			want: "# project skills\n\nversion: 1\nagents:\n  - codex\nskillMap:\n  beta: {}\n  zeta: {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), projectConfigName)
+			if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := readProjectConfigFile(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.SkillMap[tt.add] = SkillConfig{}
+			cfg.Version = projectConfigVersion
+			data, err := marshalPreserving(path, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", data, tt.want)
+			}
+		})
+	}
+}